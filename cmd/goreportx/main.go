@@ -8,6 +8,7 @@ import (
 	"html/template"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/ozgen/goreportx/pkg/core"
@@ -28,6 +29,10 @@ func Run(args []string) error {
 	showPageNumber := fs.Bool("showPageNumber", true, "Show page numbers (PDF only)")
 	withTimestamp := fs.Bool("with-timestamp", false, "Include timestamp")
 	timeFormat := fs.String("time-format", "2006-01-02 15:04:05", "Timestamp format")
+	userPassword := fs.String("user-password", "", "Password required to open the PDF (optional; weak 40-bit RC4 encryption, not for confidential content)")
+	ownerPassword := fs.String("owner-password", "", "Password that lifts PDF restrictions (optional)")
+	permissions := fs.String("permissions", "print", "Comma-separated permissions of a protected PDF: print, copy, modify, or none")
+	attachInput := fs.Bool("attach-input", false, "Embed the input JSON file in the PDF (PDF only; not with passwords)")
 
 	if err := fs.Parse(args); err != nil {
 		return err
@@ -36,6 +41,9 @@ func Run(args []string) error {
 	if *inputPath == "" || *templatePath == "" || *format == "" {
 		return errors.New("missing required flag(s): --input, --template, and --format are mandatory")
 	}
+	if *attachInput && (*userPassword != "" || *ownerPassword != "") {
+		return errors.New("--attach-input cannot be combined with --user-password or --owner-password: files cannot be attached to password-protected PDFs")
+	}
 
 	if *outputPath == "" {
 		if *format == "pdf" {
@@ -71,8 +79,20 @@ func Run(args []string) error {
 		if *baseImg != "" {
 			factory.WithBaseImage(core.LoadImageBase64(*baseImg))
 		}
+		if *userPassword != "" || *ownerPassword != "" {
+			perms, err := core.ParsePermissions(*permissions)
+			if err != nil {
+				return err
+			}
+			factory.WithProtection(*userPassword, *ownerPassword, perms)
+		}
+
+		renderer := pdf.NewPDFRenderer(report, tmpl, factory).(*pdf.PDFRenderer)
+		if *attachInput {
+			renderer.WithAttachment(filepath.Base(*inputPath), "application/json", jsonBytes)
+		}
 
-		_, err := pdf.NewPDFRenderer(report, tmpl, factory).
+		_, err := renderer.
 			WithTimestamp(*withTimestamp).
 			SetTimestampFormat(*timeFormat).
 			Render(*outputPath)
@@ -74,3 +74,68 @@ func TestRun_InvalidFormat(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid format")
 }
+
+func TestRun_InvalidPermissions(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputPath := filepath.Join(tmpDir, "input.json")
+	templatePath := filepath.Join(tmpDir, "template.html")
+	assert.NoError(t, os.WriteFile(inputPath, []byte(`{"key":"value"}`), 0644))
+	assert.NoError(t, os.WriteFile(templatePath, []byte(`<p>{{.key}}</p>`), 0644))
+
+	args := []string{
+		"--input", inputPath,
+		"--template", templatePath,
+		"--format", "pdf",
+		"--output", filepath.Join(tmpDir, "report.pdf"),
+		"--user-password", "secret",
+		"--permissions", "print,share",
+	}
+
+	err := Run(args)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown permission")
+}
+
+func TestRun_AttachInput(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inputPath := filepath.Join(tmpDir, "input.json")
+	templatePath := filepath.Join(tmpDir, "template.html")
+	outputPath := filepath.Join(tmpDir, "report.pdf")
+	assert.NoError(t, os.WriteFile(inputPath, []byte(`{"key":"value"}`), 0644))
+	assert.NoError(t, os.WriteFile(templatePath, []byte(`<p>{{.key}}</p>`), 0644))
+
+	args := []string{
+		"--input", inputPath,
+		"--template", templatePath,
+		"--format", "pdf",
+		"--output", outputPath,
+		"--attach-input",
+	}
+
+	assert.NoError(t, Run(args))
+	data, err := os.ReadFile(outputPath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "/EmbeddedFiles")
+	assert.Contains(t, string(data), "/application#2Fjson")
+}
+
+func TestRun_AttachInputWithPassword(t *testing.T) {
+	for _, flag := range []string{"--user-password", "--owner-password"} {
+		t.Run(flag, func(t *testing.T) {
+			args := []string{
+				"--input", "missing.json",
+				"--template", "missing.html",
+				"--format", "pdf",
+				"--attach-input",
+				flag, "secret",
+			}
+
+			// The flags are rejected before the input is even read.
+			err := Run(args)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "--attach-input cannot be combined")
+		})
+	}
+}
@@ -0,0 +1,144 @@
+// File: renderer/attachments.go
+package core
+
+import (
+	"bytes"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// checkAttachments reports attachments that cannot be embedded before anything
+// is rendered.
+func (r *Renderer) checkAttachments() error {
+	if len(r.Attachments) == 0 {
+		return nil
+	}
+	if r.Protection != nil {
+		return errors.New("files cannot be attached to password-protected PDFs")
+	}
+	seen := map[string]bool{}
+	for _, a := range r.Attachments {
+		if a.Name == "" {
+			return errors.New("attachment name is required")
+		}
+		if seen[a.Name] {
+			return fmt.Errorf("duplicate attachment name %q", a.Name)
+		}
+		seen[a.Name] = true
+	}
+	return nil
+}
+
+// addAttachments embeds files in a rendered PDF. Each file gets a file
+// specification marked as the data the document was produced from, listed in
+// the document's embedded files, where viewers show it as an attachment, and
+// in its associated files.
+func addAttachments(pdf []byte, attachments []Attachment, modified time.Time) ([]byte, error) {
+	if bytes.Contains(pdf, []byte("/Encrypt")) {
+		return nil, errors.New("files cannot be attached to password-protected PDFs")
+	}
+	u, err := newPDFUpdate(pdf)
+	if err != nil {
+		return nil, err
+	}
+	rootID, err := u.root()
+	if err != nil {
+		return nil, err
+	}
+	catalog, err := u.dict(rootID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Names in a name tree are sorted by their bytes.
+	sorted := append([]Attachment(nil), attachments...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return pdfTextString(sorted[i].Name) < pdfTextString(sorted[j].Name)
+	})
+
+	var names, files []string
+	for _, a := range sorted {
+		sum := md5.Sum(a.Data)
+		params := fmt.Sprintf("<< /Size %d /ModDate %s /CheckSum <%X> >>", len(a.Data), pdfDate(modified), sum)
+		stream := []dictEntry{
+			{key: "Type", value: []byte("/EmbeddedFile")},
+			{key: "Params", value: []byte(params)},
+		}
+		if a.MimeType != "" {
+			stream = dictSet(stream, "Subtype", pdfName(a.MimeType))
+		}
+		fileID := u.add(flateStreamObject(stream, a.Data))
+
+		spec := []dictEntry{
+			{key: "Type", value: []byte("/Filespec")},
+			{key: "F", value: []byte(pdfTextString(a.Name))},
+			{key: "UF", value: []byte(pdfTextString(a.Name))},
+			{key: "EF", value: []byte(fmt.Sprintf("<< /F %s /UF %s >>", ref(fileID), ref(fileID)))},
+			{key: "AFRelationship", value: []byte("/Data")},
+		}
+		if a.Description != "" {
+			spec = dictSet(spec, "Desc", pdfTextString(a.Description))
+		}
+		specID := u.add(formatDict(spec))
+		names = append(names, pdfTextString(a.Name)+" "+ref(specID))
+		files = append(files, ref(specID))
+	}
+
+	var nameDict []dictEntry
+	value := dictGet(catalog, "Names")
+	namesID, indirect := refID(value)
+	switch {
+	case indirect:
+		nameDict, err = u.dict(namesID)
+	case value != nil:
+		nameDict, err = parseDict(value)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if dictGet(nameDict, "EmbeddedFiles") != nil {
+		return nil, errors.New("PDF already has embedded files")
+	}
+	nameDict = dictSet(nameDict, "EmbeddedFiles", "<< /Names ["+strings.Join(names, " ")+"] >>")
+	if indirect {
+		u.set(namesID, formatDict(nameDict))
+	} else {
+		catalog = dictSet(catalog, "Names", string(formatDict(nameDict)))
+	}
+	catalog = dictSet(catalog, "AF", "["+strings.Join(files, " ")+"]")
+	u.set(rootID, formatDict(catalog))
+	return u.bytes(), nil
+}
+
+// pdfName encodes s as a PDF name object, escaping delimiters, "#", and
+// characters outside printable ASCII, e.g. "application/json" becomes
+// "/application#2Fjson".
+func pdfName(s string) string {
+	var b strings.Builder
+	b.WriteByte('/')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < '!' || c > '~' || strings.IndexByte("#()<>[]{}/%", c) >= 0 {
+			fmt.Fprintf(&b, "#%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// pdfDate formats t as a PDF date string such as "(D:20240102150405+01'00')".
+// The offset is written from the zone, as time layouts cannot put the minutes
+// between apostrophes.
+func pdfDate(t time.Time) string {
+	_, offset := t.Zone()
+	sign := '+'
+	if offset < 0 {
+		sign, offset = '-', -offset
+	}
+	return fmt.Sprintf("(D:%s%c%02d'%02d')", t.Format("20060102150405"), sign, offset/3600, offset%3600/60)
+}
@@ -0,0 +1,102 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderHTMLLikeToBuffer_Attachments(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	r.Attachments = []Attachment{
+		{Name: "data.json", MimeType: "application/json", Data: []byte(`{"total":42}`), Description: "Report data"},
+		{Name: "data.csv", MimeType: "text/csv", Data: []byte("total\n42\n")},
+	}
+
+	buf, err := r.RenderHTMLLikeToBuffer(`<p>Report</p>`)
+	require.NoError(t, err)
+
+	u, err := newPDFUpdate(buf.Bytes())
+	require.NoError(t, err)
+	rootID, _ := u.root()
+	catalog, err := u.dict(rootID)
+	require.NoError(t, err)
+	specs := arrayRefs(dictGet(catalog, "AF"))
+	require.Len(t, specs, 2)
+
+	names, err := parseDict(dictGet(catalog, "Names"))
+	require.NoError(t, err)
+	tree, err := parseDict(dictGet(names, "EmbeddedFiles"))
+	require.NoError(t, err)
+	csvName, jsonName := pdfTextString("data.csv"), pdfTextString("data.json")
+	assert.Equal(t, "["+csvName+" "+ref(specs[0])+" "+jsonName+" "+ref(specs[1])+"]", string(dictGet(tree, "Names")), "sorted by name")
+
+	spec, err := u.dict(specs[1])
+	require.NoError(t, err)
+	assert.Equal(t, jsonName, string(dictGet(spec, "UF")))
+	assert.Equal(t, "/Data", string(dictGet(spec, "AFRelationship")))
+	assert.Equal(t, pdfTextString("Report data"), string(dictGet(spec, "Desc")))
+
+	ef, err := parseDict(dictGet(spec, "EF"))
+	require.NoError(t, err)
+	fileID, ok := refID(dictGet(ef, "F"))
+	require.True(t, ok)
+	file, data, err := u.stream(fileID)
+	require.NoError(t, err)
+	assert.Equal(t, "/application#2Fjson", string(dictGet(file, "Subtype")))
+	assert.Equal(t, `{"total":42}`, string(data))
+	params, err := parseDict(dictGet(file, "Params"))
+	require.NoError(t, err)
+	assert.Equal(t, "12", string(dictGet(params, "Size")))
+}
+
+func TestRenderHTMLLikeToBuffer_AttachmentsRejected(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	data := Attachment{Name: "data.json", Data: []byte(`{}`)}
+
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	r.PDFA = true
+	r.Attachments = []Attachment{data}
+	_, err := r.RenderHTMLLikeToBuffer(`<p>Report</p>`)
+	assert.ErrorIs(t, err, ErrPDFANonCompliant)
+
+	r, _ = NewRenderer(defaultFontSizes(), true)
+	r.Protection = &Protection{UserPassword: "secret"}
+	r.Attachments = []Attachment{data}
+	_, err = r.RenderHTMLLikeToBuffer(`<p>Report</p>`)
+	assert.ErrorContains(t, err, "password-protected")
+
+	r, _ = NewRenderer(defaultFontSizes(), true)
+	r.Attachments = []Attachment{data, data}
+	_, err = r.RenderHTMLLikeToBuffer(`<p>Report</p>`)
+	assert.ErrorContains(t, err, `duplicate attachment name "data.json"`)
+
+	r, _ = NewRenderer(defaultFontSizes(), true)
+	r.Attachments = []Attachment{{Data: []byte(`{}`)}}
+	_, err = r.RenderHTMLLikeToBuffer(`<p>Report</p>`)
+	assert.ErrorContains(t, err, "attachment name is required")
+}
+
+func TestPDFName(t *testing.T) {
+	assert.Equal(t, "/application#2Fjson", pdfName("application/json"))
+	assert.Equal(t, "/text#2Fcsv;#20charset=utf-8", pdfName("text/csv; charset=utf-8"))
+	assert.Equal(t, "/a#23b", pdfName("a#b"))
+}
+
+func TestPDFDate(t *testing.T) {
+	date := time.Date(2024, 1, 2, 15, 4, 5, 0, time.FixedZone("", 3600))
+	assert.Equal(t, "(D:20240102150405+01'00')", pdfDate(date))
+
+	date = time.Date(2024, 1, 2, 15, 4, 5, 0, time.FixedZone("", 5*3600+1800))
+	assert.Equal(t, "(D:20240102150405+05'30')", pdfDate(date))
+
+	date = time.Date(2024, 1, 2, 15, 4, 5, 0, time.FixedZone("", -(3*3600+1800)))
+	assert.Equal(t, "(D:20240102150405-03'30')", pdfDate(date))
+}
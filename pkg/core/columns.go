@@ -0,0 +1,160 @@
+// File: renderer/columns.go
+package core
+
+import (
+	"log"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// columnLayout is the state of a multi-column section while it is laid out.
+// Content fills each column top to bottom before moving to the next one, and
+// to a new page after the last column.
+type columnLayout struct {
+	count int     // Number of columns.
+	gap   float64 // Gutter between adjacent columns.
+	left  float64 // Left edge of the first column.
+	width float64 // Width of each column.
+
+	top   float64 // Top of the columns on the current page.
+	index int     // Column currently being filled.
+	page  int     // Pages started by the section so far, counted from zero.
+	maxY  float64 // Lowest position reached by any column on the current page.
+	used  float64 // Height filled in the finished columns of the current page.
+
+	balancePage   int     // Page (counted like page) whose columns are balanced, or -1.
+	balanceBottom float64 // Bottom of the columns on the balanced page.
+}
+
+// bottom returns the lowest position content may reach in the current column.
+func (c *columnLayout) bottom() float64 {
+	if c.page == c.balancePage {
+		return c.balanceBottom
+	}
+	return contentLimit
+}
+
+// columnsStyle reads the column-count and column-gap styles of n. The gap
+// defaults to 1em, like CSS "normal".
+func (r *Renderer) columnsStyle(n *html.Node) (int, float64) {
+	val, ok := styleProperty(n, "column-count")
+	if !ok {
+		return 0, 0
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(val))
+	if err != nil {
+		return 0, 0
+	}
+	gap := r.FontSize.P
+	if val, ok := styleProperty(n, "column-gap"); ok {
+		if v, ok := parseLength(val); ok {
+			gap = v
+		}
+	}
+	return count, gap
+}
+
+// renderColumns lays out the children of n in count columns separated by gap.
+// Columns are filled in turn and continue on the next page; on the page where
+// the section ends, the columns are balanced to roughly equal heights so that
+// the content after the section starts right below the longest column.
+// Column sections nested in other column sections or in rows are laid out as
+// a single column.
+func (r *Renderer) renderColumns(n *html.Node, count int, gap float64) {
+	width := (r.width - gap*float64(count-1)) / float64(count)
+	if count < 2 || r.columns != nil || r.inRow || width <= 0 {
+		r.walkChildren(n)
+		return
+	}
+	r.checkPageBreak(r.FontSize.P * 4 / 3)
+
+	cols := columnLayout{
+		count:       count,
+		gap:         gap,
+		left:        r.left,
+		width:       width,
+		top:         r.y,
+		balancePage: -1,
+	}
+
+	// Find the page the section ends on with full columns and how much of it
+	// the content fills, then spread that evenly over the columns. Blocks do
+	// not split at arbitrary heights, so the columns are lengthened, by one
+	// line and then by doubling steps, until the content fits on that page;
+	// this keeps the number of times the section is laid out small.
+	last, used := r.measureColumns(n, cols)
+	cols.balancePage = last
+	top := cols.top
+	if last > 0 {
+		top = r.pageTop
+	}
+	bottom := top + used/float64(count)
+	for step := r.FontSize.P * 4 / 3; bottom < contentLimit; step *= 2 {
+		cols.balanceBottom = bottom
+		if page, _ := r.measureColumns(n, cols); page == last {
+			break
+		}
+		bottom += step
+	}
+	cols.balanceBottom = min(bottom, contentLimit)
+
+	left, colWidth := r.left, r.width
+	r.columns = &cols
+	r.width = width
+	r.walkChildren(n)
+	r.y = max(cols.maxY, r.y)
+	r.columns = nil
+	r.left, r.width = left, colWidth
+}
+
+// measureColumns lays out the children of n on the scratch PDF with the given
+// column settings and returns the page, counted from zero, the section ends
+// on, and the total height of the columns filled on that page.
+func (r *Renderer) measureColumns(n *html.Node, cols columnLayout) (int, float64) {
+	scratch, err := r.scratchPDF()
+	if err != nil {
+		log.Println("Column balancing skipped:", err)
+		return cols.balancePage, 0
+	}
+
+	pdf, y, left, width, measuring := r.pdf, r.y, r.left, r.width, r.measuring
+	r.pdf, r.y, r.left, r.width, r.measuring = scratch, cols.top, cols.left, cols.width, true
+	r.columns = &cols
+	defer func() {
+		r.pdf, r.y, r.left, r.width, r.measuring = pdf, y, left, width, measuring
+		r.columns = nil
+	}()
+
+	r.walkChildren(n)
+	return cols.page, cols.used + r.y - cols.top
+}
+
+// nextColumn moves the layout to the top of the next column, or to the first
+// column of a new page after the last one. While measuring, the new page is
+// only simulated.
+func (r *Renderer) nextColumn() {
+	c := r.columns
+	previous := c.index
+	c.maxY = max(c.maxY, r.y)
+	c.used += r.y - c.top
+	if c.index < c.count-1 {
+		c.index++
+	} else {
+		if r.measuring {
+			r.y = r.pageTop
+		} else {
+			r.flushPage()
+		}
+		c.index = 0
+		c.page++
+		c.top = r.y
+		c.maxY = 0
+		c.used = 0
+	}
+	// Indentation, e.g. of list items, carries over to the next column.
+	indent := r.left - (c.left + float64(previous)*(c.width+c.gap))
+	r.left = c.left + float64(c.index)*(c.width+c.gap) + indent
+	r.y = c.top
+}
@@ -0,0 +1,102 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/html"
+)
+
+func TestColumnsStyle(t *testing.T) {
+	doc, _ := html.Parse(strings.NewReader(`
+		<div style="column-count: 3; column-gap: 24px"></div>
+		<div style="column-count: 2"></div>
+		<div></div>`))
+	r := &Renderer{FontSize: defaultFontSizes()}
+	divs := findElements(doc, "div")
+
+	count, gap := r.columnsStyle(divs[0])
+	assert.Equal(t, 3, count)
+	assert.Equal(t, 24.0, gap)
+
+	count, gap = r.columnsStyle(divs[1])
+	assert.Equal(t, 2, count)
+	assert.Equal(t, r.FontSize.P, gap, "the gap defaults to 1em")
+
+	count, _ = r.columnsStyle(divs[2])
+	assert.Equal(t, 0, count)
+}
+
+func columnParagraphs(n int) string {
+	var body strings.Builder
+	body.WriteString(`<div style="column-count: 2">`)
+	for i := 0; i < n; i++ {
+		body.WriteString("<p>Short paragraph</p>")
+	}
+	body.WriteString(`</div>`)
+	return body.String()
+}
+
+func TestRenderColumns_Balanced(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	doc, _ := html.Parse(strings.NewReader(columnParagraphs(10)))
+
+	r.walk(doc)
+
+	paragraph := r.FontSize.P*4/3 + 4
+	assert.InDelta(t, 50+5*paragraph, r.y, 1, "ten paragraphs end up five per column")
+	assert.Equal(t, 1, r.pageNumber)
+	assert.Nil(t, r.columns)
+	assert.Equal(t, 50.0, r.left)
+	assert.Equal(t, 495.0, r.width)
+}
+
+func TestRenderColumns_AcrossPages(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	doc, _ := html.Parse(strings.NewReader(columnParagraphs(100)))
+
+	r.walk(doc)
+
+	paragraph := r.FontSize.P*4/3 + 4
+	perColumn := int((contentLimit - 50) / paragraph)
+	remaining := 100 - 2*perColumn
+	assert.Equal(t, 2, r.pageNumber, "full columns on the first page")
+	assert.InDelta(t, 50+float64((remaining+1)/2)*paragraph, r.y, 1, "the last page is balanced")
+}
+
+func TestRenderColumns_LargeDocument(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	doc, _ := html.Parse(strings.NewReader(columnParagraphs(1001)))
+
+	r.walk(doc)
+
+	paragraph := r.FontSize.P*4/3 + 4
+	perPage := 2 * int((contentLimit-50)/paragraph)
+	pages := (1001 + perPage - 1) / perPage
+	remaining := 1001 - (pages-1)*perPage
+	assert.Equal(t, pages, r.pageNumber)
+	assert.InDelta(t, 50+float64((remaining+1)/2)*paragraph, r.y, 1, "the last page is balanced")
+}
+
+func TestRenderHTMLLikeToBuffer_DocumentColumns(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	r.Columns = 2
+
+	_, err := r.RenderHTMLLikeToBuffer(`<h2 id="title">Two columns</h2><p>Left</p><p>Right</p>`)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, r.anchorPages["title"])
+	assert.Equal(t, 1, r.pageNumber)
+}
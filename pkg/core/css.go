@@ -0,0 +1,225 @@
+// File: renderer/css.go
+package core
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// namedColors lists the CSS color keywords recognized by parseColor.
+var namedColors = map[string]Color{
+	"black":  {0, 0, 0},
+	"white":  {255, 255, 255},
+	"gray":   {128, 128, 128},
+	"grey":   {128, 128, 128},
+	"silver": {192, 192, 192},
+	"red":    {255, 0, 0},
+	"maroon": {128, 0, 0},
+	"orange": {255, 165, 0},
+	"yellow": {255, 255, 0},
+	"green":  {0, 128, 0},
+	"lime":   {0, 255, 0},
+	"teal":   {0, 128, 128},
+	"blue":   {0, 0, 255},
+	"navy":   {0, 0, 128},
+	"purple": {128, 0, 128},
+}
+
+// getAttr returns the value of the named attribute of an element, or an empty string.
+func getAttr(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// hasAttr reports whether an element has the named attribute, such as the
+// boolean checked or readonly attributes.
+func hasAttr(n *html.Node, key string) bool {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// hasClass reports whether the element's class attribute contains the given class name.
+func hasClass(n *html.Node, class string) bool {
+	for _, name := range strings.Fields(getAttr(n, "class")) {
+		if name == class {
+			return true
+		}
+	}
+	return false
+}
+
+// styleProperty looks up a property in the element's inline style attribute.
+// Property names are matched case-insensitively and the value is returned trimmed.
+func styleProperty(n *html.Node, name string) (string, bool) {
+	for _, decl := range strings.Split(getAttr(n, "style"), ";") {
+		key, val, ok := strings.Cut(decl, ":")
+		if !ok {
+			continue
+		}
+		if strings.EqualFold(strings.TrimSpace(key), name) {
+			return strings.TrimSpace(val), true
+		}
+	}
+	return "", false
+}
+
+// parseColor parses a CSS color in "#rgb", "#rrggbb", "rgb(r, g, b)" or
+// named-keyword form. It reports false when the value is not recognized.
+func parseColor(s string) (Color, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if c, ok := namedColors[s]; ok {
+		return c, true
+	}
+
+	if strings.HasPrefix(s, "#") {
+		hex := s[1:]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		if len(hex) != 6 {
+			return Color{}, false
+		}
+		v, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return Color{}, false
+		}
+		return Color{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v)}, true
+	}
+
+	if strings.HasPrefix(s, "rgb(") && strings.HasSuffix(s, ")") {
+		parts := strings.Split(s[4:len(s)-1], ",")
+		if len(parts) != 3 {
+			return Color{}, false
+		}
+		var rgb [3]uint8
+		for i, p := range parts {
+			v, err := strconv.Atoi(strings.TrimSpace(p))
+			if err != nil || v < 0 || v > 255 {
+				return Color{}, false
+			}
+			rgb[i] = uint8(v)
+		}
+		return Color{R: rgb[0], G: rgb[1], B: rgb[2]}, true
+	}
+
+	return Color{}, false
+}
+
+// parseLength parses a CSS length such as "12", "12px" or "12pt" into points.
+// Pixels are treated as points, matching how the layout engine sizes images.
+func parseLength(s string) (float64, bool) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "px"), "pt")
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || v < 0 {
+		return 0, false
+	}
+	return v, true
+}
+
+// parseSize parses a CSS width that is either a length or a percentage of total.
+func parseSize(s string, total float64) (float64, bool) {
+	if pct, ok := strings.CutSuffix(strings.TrimSpace(s), "%"); ok {
+		v, err := strconv.ParseFloat(strings.TrimSpace(pct), 64)
+		if err != nil || v < 0 {
+			return 0, false
+		}
+		return total * v / 100, true
+	}
+	return parseLength(s)
+}
+
+// textAlign resolves the text-align that applies to n: the element's own inline
+// style, then the element's default (h1 is centered), then the nearest ancestor
+// with an inline text-align, since the property is inherited. It falls back to
+// AlignLeft.
+func textAlign(n *html.Node) Alignment {
+	for node := n; node != nil; node = node.Parent {
+		if node.Type != html.ElementNode {
+			continue
+		}
+		if val, ok := styleProperty(node, "text-align"); ok {
+			switch align := Alignment(strings.ToLower(val)); align {
+			case AlignLeft, AlignCenter, AlignRight, AlignJustify:
+				return align
+			}
+		}
+		if node == n && node.Data == "h1" {
+			return AlignCenter
+		}
+	}
+	return AlignLeft
+}
+
+// forcesPageBreak reports whether n requests a page break on the given side
+// ("before" or "after"), through page-break-before/after: always or the
+// newer break-before/after: page.
+func forcesPageBreak(n *html.Node, side string) bool {
+	for _, name := range []string{"page-break-" + side, "break-" + side} {
+		if val, ok := styleProperty(n, name); ok {
+			switch strings.ToLower(val) {
+			case "always", "page", "left", "right":
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// avoidsPageBreakInside reports whether n asks to be kept on a single page,
+// through page-break-inside: avoid or break-inside: avoid.
+func avoidsPageBreakInside(n *html.Node) bool {
+	for _, name := range []string{"page-break-inside", "break-inside"} {
+		if val, ok := styleProperty(n, name); ok {
+			switch strings.ToLower(val) {
+			case "avoid", "avoid-page":
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// keepsWithNext reports whether n must stay on the same page as the element
+// that follows it: headings do unless styled page-break-after: auto, and any
+// element styled page-break-after: avoid (or break-after: avoid) does.
+func keepsWithNext(n *html.Node) bool {
+	for _, name := range []string{"page-break-after", "break-after"} {
+		if val, ok := styleProperty(n, name); ok {
+			switch strings.ToLower(val) {
+			case "avoid", "avoid-page":
+				return true
+			case "auto":
+				return false
+			}
+		}
+	}
+	switch n.Data {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		return true
+	}
+	return false
+}
+
+// styleInt parses a non-negative integer CSS property such as orphans or widows.
+func styleInt(n *html.Node, name string) (int, bool) {
+	val, ok := styleProperty(n, name)
+	if !ok {
+		return 0, false
+	}
+	v, err := strconv.Atoi(val)
+	if err != nil || v < 0 {
+		return 0, false
+	}
+	return v, true
+}
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/html"
+)
+
+func TestParseColor(t *testing.T) {
+	cases := map[string]Color{
+		"#ff8000":          {255, 128, 0},
+		"#F80":             {255, 136, 0},
+		"rgb(1, 2, 3)":     {1, 2, 3},
+		"  Navy ":          {0, 0, 128},
+		"rgb(0,128,255)":   {0, 128, 255},
+		"#000000":          {0, 0, 0},
+		"rgb( 10 ,20, 30)": {10, 20, 30},
+	}
+	for in, want := range cases {
+		got, ok := parseColor(in)
+		assert.True(t, ok, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	for _, in := range []string{"", "#12", "#zzzzzz", "rgb(1,2)", "rgb(300,0,0)", "chartreuse-ish"} {
+		_, ok := parseColor(in)
+		assert.False(t, ok, in)
+	}
+}
+
+func TestStyleProperty(t *testing.T) {
+	doc, _ := html.Parse(strings.NewReader(`<p style="Color: red ; text-align:center">x</p>`))
+	p := findNode(doc, "p")
+
+	val, ok := styleProperty(p, "color")
+	assert.True(t, ok)
+	assert.Equal(t, "red", val)
+
+	val, ok = styleProperty(p, "text-align")
+	assert.True(t, ok)
+	assert.Equal(t, "center", val)
+
+	_, ok = styleProperty(p, "width")
+	assert.False(t, ok)
+}
+
+func TestParseLength(t *testing.T) {
+	v, ok := parseLength("12px")
+	assert.True(t, ok)
+	assert.Equal(t, 12.0, v)
+
+	v, ok = parseLength("7.5pt")
+	assert.True(t, ok)
+	assert.Equal(t, 7.5, v)
+
+	_, ok = parseLength("auto")
+	assert.False(t, ok)
+}
+
+func TestTextAlign(t *testing.T) {
+	doc, _ := html.Parse(strings.NewReader(`
+		<h1>Centered by default</h1>
+		<div style="text-align: right"><p id="inherit">x</p><h1 id="h1">y</h1></div>
+		<p style="text-align: JUSTIFY">z</p>
+		<p style="text-align: start">w</p>`))
+
+	assert.Equal(t, AlignCenter, textAlign(findNode(doc, "h1")))
+
+	div := findNode(doc, "div")
+	assert.Equal(t, AlignRight, textAlign(findNode(div, "p")))
+	assert.Equal(t, AlignCenter, textAlign(findNode(div, "h1")))
+
+	var paragraphs []*html.Node
+	for p := findNode(doc, "body").FirstChild; p != nil; p = p.NextSibling {
+		if p.Type == html.ElementNode && p.Data == "p" {
+			paragraphs = append(paragraphs, p)
+		}
+	}
+	assert.Equal(t, AlignJustify, textAlign(paragraphs[0]))
+	assert.Equal(t, AlignLeft, textAlign(paragraphs[1]))
+}
+
+func TestPageBreakStyles(t *testing.T) {
+	doc, _ := html.Parse(strings.NewReader(`
+		<div id="a" style="page-break-before: always"></div>
+		<div id="b" style="break-after: page; page-break-inside: avoid"></div>
+		<div id="c" style="page-break-before: auto; break-inside: auto"></div>`))
+
+	divs := findElements(doc, "div")
+	assert.True(t, forcesPageBreak(divs[0], "before"))
+	assert.False(t, forcesPageBreak(divs[0], "after"))
+	assert.True(t, forcesPageBreak(divs[1], "after"))
+	assert.True(t, avoidsPageBreakInside(divs[1]))
+	assert.False(t, forcesPageBreak(divs[2], "before"))
+	assert.False(t, avoidsPageBreakInside(divs[2]))
+}
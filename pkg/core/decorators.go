@@ -0,0 +1,201 @@
+// File: renderer/decorators.go
+package core
+
+import (
+	"log"
+
+	"github.com/signintech/gopdf"
+)
+
+// PageDecorator draws on every page of the document, independent of its
+// content. Decorators run in the order they are registered:
+//
+//   - OnPageStart is called as each page is started, before any content is
+//     drawn on it. TotalPages is not known yet and is zero.
+//   - OnPageEnd is called for every page once the whole document has been laid
+//     out, so TotalPages is set and anything drawn ends up above the content.
+//
+// Running headers and footers are drawn before the OnPageEnd hooks.
+type PageDecorator interface {
+	OnPageStart(ctx PageContext)
+	OnPageEnd(ctx PageContext)
+}
+
+// PageContext describes the page a PageDecorator is drawing on.
+type PageContext struct {
+	PDF        *gopdf.GoPdf // Document being rendered, with the page selected.
+	Page       int          // 1-based physical page number.
+	TotalPages int          // Number of pages in the document; zero in OnPageStart.
+	Width      float64      // Page width in points.
+	Height     float64      // Page height in points.
+	FontSizes  FontSizes    // Font size configuration of the document.
+
+	r *Renderer
+}
+
+// ContentTop returns the vertical position where content starts on the page.
+func (c PageContext) ContentTop() float64 {
+	return c.r.y
+}
+
+// SetContentTop moves the start of content on the page, e.g. below a banner
+// drawn in OnPageStart. It has no effect in OnPageEnd.
+func (c PageContext) SetContentTop(y float64) {
+	if c.TotalPages == 0 {
+		c.r.y = y
+	}
+}
+
+// DrawText draws text in the regular document font at the given size, with
+// its top-left corner at (x, y).
+func (c PageContext) DrawText(text string, x, y, fontSize float64) {
+	_ = c.PDF.SetFont("Arial", "", fontSize)
+	c.PDF.SetX(x)
+	c.PDF.SetY(y)
+	if err := c.PDF.Cell(nil, text); err != nil {
+		log.Println("Decorator text render failed:", err)
+	}
+}
+
+// DefaultPageDecorators returns the built-in decorators in the order they are
+// drawn: template PDF page, background image, watermark, header and footer
+// images, footer text, timestamp, and page number. Each one is driven by the renderer's settings
+// and draws nothing when its feature is not configured.
+func DefaultPageDecorators() []PageDecorator {
+	return []PageDecorator{
+		TemplatePDFDecorator{},
+		BackgroundImageDecorator{},
+		WatermarkDecorator{},
+		HeaderImageDecorator{},
+		FooterImageDecorator{},
+		FooterTextDecorator{},
+		TimestampDecorator{},
+		PageNumberDecorator{},
+	}
+}
+
+// BackgroundImageDecorator draws the background image, placed by
+// BackgroundImageOptions, beneath the content.
+type BackgroundImageDecorator struct{}
+
+func (BackgroundImageDecorator) OnPageStart(ctx PageContext) {
+	ctx.r.drawPageImage(ctx.r.backgroundImg, ctx.r.BackgroundImageOptions)
+}
+
+func (BackgroundImageDecorator) OnPageEnd(PageContext) {}
+
+// HeaderImageDecorator draws the header image, placed by HeaderImageOptions.
+// Content starts below a header band of the image's height plus 10pt under
+// the page margin, 100pt with the default options, or 10pt below the drawn
+// image when it is placed lower.
+type HeaderImageDecorator struct{}
+
+func (HeaderImageDecorator) OnPageStart(ctx PageContext) {
+	opts := ctx.r.HeaderImageOptions
+	if bottom := ctx.r.drawPageImage(ctx.r.headerImg, opts); bottom > 0 {
+		ctx.SetContentTop(max(pageMargin+opts.Height+10, bottom+10))
+	}
+}
+
+func (HeaderImageDecorator) OnPageEnd(PageContext) {}
+
+// FooterImageDecorator draws the footer image, placed by FooterImageOptions.
+type FooterImageDecorator struct{}
+
+func (FooterImageDecorator) OnPageStart(ctx PageContext) {
+	ctx.r.drawPageImage(ctx.r.footerImg, ctx.r.FooterImageOptions)
+}
+
+func (FooterImageDecorator) OnPageEnd(PageContext) {}
+
+// FooterTextDecorator draws the text of the document's <div class="footer">
+// at the bottom of the page.
+type FooterTextDecorator struct{}
+
+func (FooterTextDecorator) OnPageStart(PageContext) {}
+
+func (FooterTextDecorator) OnPageEnd(ctx PageContext) {
+	ctx.r.drawFooterAtFixedPosition()
+}
+
+// TimestampDecorator draws TopRightTimestamp in the top-right of the page.
+type TimestampDecorator struct{}
+
+func (TimestampDecorator) OnPageStart(PageContext) {}
+
+func (TimestampDecorator) OnPageEnd(ctx PageContext) {
+	ctx.r.drawTimestamp()
+}
+
+// PageNumberDecorator draws the page number, formatted by PageNumbers, when
+// page numbers are enabled.
+type PageNumberDecorator struct{}
+
+func (PageNumberDecorator) OnPageStart(PageContext) {}
+
+func (PageNumberDecorator) OnPageEnd(ctx PageContext) {
+	ctx.r.drawPageNumber(ctx.Page, ctx.TotalPages)
+}
+
+// WatermarkDecorator draws the Watermark beneath the content, or above it
+// when Above is set.
+type WatermarkDecorator struct{}
+
+func (WatermarkDecorator) OnPageStart(ctx PageContext) {
+	if w := ctx.r.Watermark; w != nil && !w.Above {
+		ctx.r.drawWatermark()
+	}
+}
+
+func (WatermarkDecorator) OnPageEnd(ctx PageContext) {
+	if w := ctx.r.Watermark; w != nil && w.Above {
+		ctx.r.drawWatermark()
+	}
+}
+
+// pageContext returns the context passed to decorators for the given page.
+func (r *Renderer) pageContext(page, total int) PageContext {
+	return PageContext{
+		PDF:        r.pdf,
+		Page:       page,
+		TotalPages: total,
+		Width:      595.28,
+		Height:     841.89,
+		FontSizes:  r.FontSize,
+		r:          r,
+	}
+}
+
+// startPage runs the OnPageStart hooks on the page that was just started.
+// Content starts at the top margin unless a decorator moves it down.
+func (r *Renderer) startPage() {
+	r.beginArtifact()
+	// gopdf creates a page's content stream on first use; an untouched page
+	// would be written without one and be skipped by SetPage.
+	r.pdf.SetLineWidth(1)
+	r.y = pageMargin
+	ctx := r.pageContext(r.pageNumber, 0)
+	for _, d := range r.Decorators {
+		d.OnPageStart(ctx)
+	}
+	r.endArtifact()
+	r.pageTop = r.y
+}
+
+// finishPages runs the OnPageEnd hooks on every page once layout is complete,
+// revisiting each page's content stream and returning to the last page.
+func (r *Renderer) finishPages() {
+	for page := 1; page <= r.pageNumber; page++ {
+		if err := r.pdf.SetPage(page); err != nil {
+			log.Println("Page decorators skipped:", err)
+			continue
+		}
+		ctx := r.pageContext(page, r.pageNumber)
+		r.beginArtifact()
+		for _, d := range r.Decorators {
+			d.OnPageEnd(ctx)
+		}
+		r.endArtifact()
+	}
+	_ = r.pdf.SetPage(r.pageNumber)
+}
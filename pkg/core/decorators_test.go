@@ -0,0 +1,81 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingDecorator records the pages its hooks are called for.
+type recordingDecorator struct {
+	starts, ends [][2]int
+}
+
+func (d *recordingDecorator) OnPageStart(ctx PageContext) {
+	d.starts = append(d.starts, [2]int{ctx.Page, ctx.TotalPages})
+}
+
+func (d *recordingDecorator) OnPageEnd(ctx PageContext) {
+	d.ends = append(d.ends, [2]int{ctx.Page, ctx.TotalPages})
+}
+
+// bannerDecorator draws a banner at the top of every page and moves the content below it.
+type bannerDecorator struct{}
+
+func (bannerDecorator) OnPageStart(ctx PageContext) {
+	ctx.DrawText("SECRET", ctx.Width/2, 20, 14)
+	ctx.SetContentTop(ctx.ContentTop() + 70)
+}
+
+func (bannerDecorator) OnPageEnd(ctx PageContext) {
+	ctx.SetContentTop(0)
+}
+
+func TestRenderHTMLLikeToBuffer_PageDecoratorHooks(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	rec := &recordingDecorator{}
+	r.Decorators = append(r.Decorators, rec)
+
+	_, err := r.RenderHTMLLikeToBuffer(`<p>One</p><pagebreak/><p>Two</p><pagebreak/><p>Three</p>`)
+	assert.NoError(t, err)
+	assert.Equal(t, [][2]int{{1, 0}, {2, 0}, {3, 0}}, rec.starts, "the total is unknown while pages are started")
+	assert.Equal(t, [][2]int{{1, 3}, {2, 3}, {3, 3}}, rec.ends)
+}
+
+func TestStartPage_DecoratorMovesContent(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	r.Decorators = append(r.Decorators, bannerDecorator{})
+
+	r.startPage()
+	assert.Equal(t, pageMargin+70, r.y)
+	assert.Equal(t, r.y, r.pageTop)
+
+	r.finishPages()
+	assert.Equal(t, pageMargin+70, r.y, "content cannot be moved once laid out")
+}
+
+func TestRenderHTMLLikeToBuffer_TimestampOnEveryPage(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), false)
+	r.TopRightTimestamp = "2025-07-01"
+	r.pdf.SetNoCompression()
+
+	buf, err := r.RenderHTMLLikeToBuffer(`<p>One</p><pagebreak/><p>Two</p><pagebreak/><p>Three</p>`)
+	assert.NoError(t, err)
+	var perPage []int
+	for _, stream := range strings.Split(buf.String(), "endstream") {
+		if n := strings.Count(stream, "/F1 10 Tf"); n > 0 {
+			perPage = append(perPage, n)
+		}
+	}
+	assert.Equal(t, []int{1, 1, 1}, perPage, "one timestamp on each page, including the first")
+}
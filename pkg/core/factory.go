@@ -15,6 +15,12 @@ type RendererFactory struct {
 	// ShowPageNumber indicates whether page numbers should be displayed on each page.
 	ShowPageNumber bool
 
+	// PageNumbers defines the page number template, numbering style, and placement.
+	PageNumbers PageNumberFormat
+
+	// Pagination defines the minimum orphan and widow lines of paragraphs.
+	Pagination Pagination
+
 	// Base64Background is a base64-encoded string representing the background image (optional).
 	Base64Background string
 
@@ -23,6 +29,63 @@ type RendererFactory struct {
 
 	// Base64Footer is a base64-encoded string representing the footer image (optional).
 	Base64Footer string
+
+	// BackgroundImageOptions places the background image on each page.
+	BackgroundImageOptions PageImageOptions
+
+	// HeaderImageOptions places the header image on each page.
+	HeaderImageOptions PageImageOptions
+
+	// FooterImageOptions places the footer image on each page.
+	FooterImageOptions PageImageOptions
+
+	// TableStyle defines header/stripe/footer fills and border styling for tables.
+	TableStyle TableStyle
+
+	// LinkStyle defines the color and underline used for hyperlink text.
+	LinkStyle LinkStyle
+
+	// TableOfContents enables a generated table of contents when non-nil (optional).
+	TableOfContents *TableOfContents
+
+	// Bookmarks enables a PDF outline built from h1–h3 headings.
+	Bookmarks bool
+
+	// Columns is the number of columns the document is laid out in (optional).
+	Columns int
+
+	// ColumnGap is the gutter between columns in points; zero uses 1em.
+	ColumnGap float64
+
+	// Watermark is drawn across every page when non-nil (optional).
+	Watermark *Watermark
+
+	// TemplatePDF is drawn beneath every page.
+	TemplatePDF *TemplatePDF
+
+	// Attachments are embedded in the PDF.
+	Attachments []Attachment
+
+	// HeaderTemplate is an HTML fragment drawn as the running header of every page (optional).
+	HeaderTemplate string
+
+	// FooterTemplate is an HTML fragment drawn as the running footer of every page (optional).
+	FooterTemplate string
+
+	// Protection encrypts the PDF when non-nil (optional).
+	Protection *Protection
+
+	// PDFA makes the output conform to PDF/A-2b.
+	PDFA bool
+
+	// Tagged adds a structure tree for assistive technology.
+	Tagged bool
+
+	// Metadata sets the PDF's document properties (optional).
+	Metadata Metadata
+
+	// Decorators are run on every page after the built-in ones (optional).
+	Decorators []PageDecorator
 }
 
 // NewRendererFactory creates a RendererFactory instance with default font sizes
@@ -36,7 +99,14 @@ func NewRendererFactory() *RendererFactory {
 			P:      12,
 			Footer: 10,
 		},
-		ShowPageNumber: true,
+		ShowPageNumber:         true,
+		PageNumbers:            DefaultPageNumberFormat(),
+		Pagination:             DefaultPagination(),
+		BackgroundImageOptions: DefaultBackgroundImageOptions(),
+		HeaderImageOptions:     DefaultHeaderImageOptions(),
+		FooterImageOptions:     DefaultFooterImageOptions(),
+		TableStyle:             DefaultTableStyle(),
+		LinkStyle:              DefaultLinkStyle(),
 	}
 }
 
@@ -52,6 +122,20 @@ func (f *RendererFactory) WithPageNumbers(show bool) *RendererFactory {
 	return f
 }
 
+// WithPageNumberFormat sets the page number template (e.g. "Page {page} of {total}"),
+// numbering style, front matter, starting offset, and position.
+func (f *RendererFactory) WithPageNumberFormat(format PageNumberFormat) *RendererFactory {
+	f.PageNumbers = format
+	return f
+}
+
+// WithPagination sets the minimum number of paragraph lines left at the bottom
+// of a page (orphans) and carried over to the next page (widows).
+func (f *RendererFactory) WithPagination(p Pagination) *RendererFactory {
+	f.Pagination = p
+	return f
+}
+
 // WithBaseImage sets a base64-encoded background image (optional).
 func (f *RendererFactory) WithBaseImage(base64 string) *RendererFactory {
 	f.Base64Background = base64
@@ -70,18 +154,202 @@ func (f *RendererFactory) WithFooterImage(base64 string) *RendererFactory {
 	return f
 }
 
+// WithBaseImageOptions sets the position, size, fit, opacity, and first-page
+// behavior of the background image.
+func (f *RendererFactory) WithBaseImageOptions(opts PageImageOptions) *RendererFactory {
+	f.BackgroundImageOptions = opts
+	return f
+}
+
+// WithHeaderImageOptions sets the position, size, fit, opacity, and first-page
+// behavior of the header image. Content starts below the header band, see
+// HeaderImageDecorator.
+func (f *RendererFactory) WithHeaderImageOptions(opts PageImageOptions) *RendererFactory {
+	f.HeaderImageOptions = opts
+	return f
+}
+
+// WithFooterImageOptions sets the position, size, fit, opacity, and first-page
+// behavior of the footer image.
+func (f *RendererFactory) WithFooterImageOptions(opts PageImageOptions) *RendererFactory {
+	f.FooterImageOptions = opts
+	return f
+}
+
+// WithTableStyle sets the styling applied to tables, such as header fill,
+// zebra striping, footer row style, and border mode.
+func (f *RendererFactory) WithTableStyle(style TableStyle) *RendererFactory {
+	f.TableStyle = style
+	return f
+}
+
+// WithLinkStyle sets the color and underline applied to hyperlink text.
+func (f *RendererFactory) WithLinkStyle(style LinkStyle) *RendererFactory {
+	f.LinkStyle = style
+	return f
+}
+
+// WithTableOfContents enables a table of contents listing h1–h3 headings with
+// their page numbers and links. It is placed at the template's <toc> element,
+// or on its own page(s) before the content when the template has none.
+func (f *RendererFactory) WithTableOfContents(toc TableOfContents) *RendererFactory {
+	f.TableOfContents = &toc
+	return f
+}
+
+// WithBookmarks toggles a nested PDF outline (bookmarks) built from h1–h3
+// headings. Individual headings can opt out with data-bookmark="false".
+func (f *RendererFactory) WithBookmarks(enable bool) *RendererFactory {
+	f.Bookmarks = enable
+	return f
+}
+
+// WithColumns lays the whole document out in count newspaper-style columns
+// separated by gap points. Sections can use column-count styles instead.
+func (f *RendererFactory) WithColumns(count int, gap float64) *RendererFactory {
+	f.Columns = count
+	f.ColumnGap = gap
+	return f
+}
+
+// WithWatermark draws the given text and/or image across every page,
+// beneath the content unless Above is set.
+func (f *RendererFactory) WithWatermark(w Watermark) *RendererFactory {
+	f.Watermark = &w
+	return f
+}
+
+// WithTemplatePDF draws page of the PDF file at path beneath every page, e.g. a
+// letterhead designed in another tool. Build fails when the file cannot be read
+// or has no such page. Cover pages and appendices are added with Merge.
+func (f *RendererFactory) WithTemplatePDF(path string, page int) *RendererFactory {
+	f.TemplatePDF = &TemplatePDF{Path: path, Page: page}
+	return f
+}
+
+// WithAttachment embeds a file in the PDF, e.g. the JSON or CSV data the report
+// was generated from. Attachments cannot be combined with PDF/A-2b or
+// password protection (WithPDFA or WithProtection); rendering then fails
+// before the report is laid out.
+func (f *RendererFactory) WithAttachment(name, mimeType string, data []byte) *RendererFactory {
+	f.Attachments = append(f.Attachments, Attachment{Name: name, MimeType: mimeType, Data: data})
+	return f
+}
+
+// WithHeaderTemplate sets an HTML fragment drawn at the top of every page.
+// Text may contain {page}, {total}, {timestamp} and {title}; wrap variants in
+// <header data-page="first|odd|even"> to vary the header by page.
+func (f *RendererFactory) WithHeaderTemplate(tmpl string) *RendererFactory {
+	f.HeaderTemplate = tmpl
+	return f
+}
+
+// WithFooterTemplate sets an HTML fragment drawn at the bottom of every page.
+// It supports the same placeholders as WithHeaderTemplate; wrap variants in
+// <footer data-page="first|odd|even">.
+func (f *RendererFactory) WithFooterTemplate(tmpl string) *RendererFactory {
+	f.FooterTemplate = tmpl
+	return f
+}
+
+// WithProtection encrypts the PDF. The user password is needed to open the
+// document (empty opens it without one); the owner password lifts the
+// restrictions and is generated at random when empty. permissions lists what
+// readers may do without the owner password, e.g. PermissionPrint|PermissionCopy.
+//
+// The encryption is gopdf's 40-bit RC4, which is weak: it deters casual
+// opening and editing, but the document can be decrypted without the
+// passwords, so it must not be relied on to keep content confidential.
+func (f *RendererFactory) WithProtection(userPassword, ownerPassword string, permissions Permission) *RendererFactory {
+	f.Protection = &Protection{
+		UserPassword:  userPassword,
+		OwnerPassword: ownerPassword,
+		Permissions:   permissions,
+	}
+	return f
+}
+
+// WithPDFA toggles PDF/A-2b output for long-term archiving: XMP metadata and
+// an sRGB output intent are added to the document. Rendering fails with
+// ErrPDFANonCompliant when the document cannot comply, e.g. when it is
+// password protected.
+func (f *RendererFactory) WithPDFA(enable bool) *RendererFactory {
+	f.PDFA = enable
+	return f
+}
+
+// WithTaggedPDF toggles tagged PDF output for accessibility: headings,
+// paragraphs, tables, lists, and images (with their alt text) are exposed to
+// screen readers in reading order, the document language is taken from
+// <html lang>, and page decorations are marked as artifacts.
+func (f *RendererFactory) WithTaggedPDF(enable bool) *RendererFactory {
+	f.Tagged = enable
+	return f
+}
+
+// WithMetadata sets the PDF's title, author, subject, keywords, creator,
+// producer, and creation date. An empty title uses the template's <title>, and
+// a zero creation date the time of rendering.
+func (f *RendererFactory) WithMetadata(m Metadata) *RendererFactory {
+	f.Metadata = m
+	return f
+}
+
+// WithPageDecorator registers a decorator that draws on every page, such as a
+// classification banner. It runs after the built-in decorators.
+func (f *RendererFactory) WithPageDecorator(d PageDecorator) *RendererFactory {
+	f.Decorators = append(f.Decorators, d)
+	return f
+}
+
 // Build creates a new Renderer instance based on the current configuration.
 // If no images are provided, it defaults to a simple text-based renderer.
 // Otherwise, it returns a renderer with the specified base64-encoded images.
 func (f *RendererFactory) Build() (*Renderer, error) {
+	var (
+		r   *Renderer
+		err error
+	)
+	if f.TemplatePDF != nil {
+		if err := checkTemplatePDF(f.TemplatePDF); err != nil {
+			return nil, err
+		}
+	}
 	if f.Base64Background == "" && f.Base64Header == "" && f.Base64Footer == "" {
-		return NewRenderer(f.FontSizes, f.ShowPageNumber)
+		r, err = NewRenderer(f.FontSizes, f.ShowPageNumber)
+	} else {
+		r, err = NewRendererWithBase64Images(
+			f.Base64Background,
+			f.Base64Header,
+			f.Base64Footer,
+			f.FontSizes,
+			f.ShowPageNumber,
+		)
 	}
-	return NewRendererWithBase64Images(
-		f.Base64Background,
-		f.Base64Header,
-		f.Base64Footer,
-		f.FontSizes,
-		f.ShowPageNumber,
-	)
+	if err != nil {
+		return nil, err
+	}
+
+	r.PageNumbers = f.PageNumbers
+	r.Pagination = f.Pagination
+	r.BackgroundImageOptions = f.BackgroundImageOptions
+	r.HeaderImageOptions = f.HeaderImageOptions
+	r.FooterImageOptions = f.FooterImageOptions
+	r.TableStyle = f.TableStyle
+	r.LinkStyle = f.LinkStyle
+	r.TableOfContents = f.TableOfContents
+	r.Bookmarks = f.Bookmarks
+	r.Columns = f.Columns
+	r.ColumnGap = f.ColumnGap
+	r.Watermark = f.Watermark
+	r.TemplatePDF = f.TemplatePDF
+	r.Attachments = append(r.Attachments, f.Attachments...)
+	r.HeaderTemplate = f.HeaderTemplate
+	r.FooterTemplate = f.FooterTemplate
+	r.Protection = f.Protection
+	r.PDFA = f.PDFA
+	r.Tagged = f.Tagged
+	r.Metadata = f.Metadata
+	r.Decorators = append(r.Decorators, f.Decorators...)
+	return r, nil
 }
@@ -21,6 +21,7 @@ func TestNewRendererFactory_Defaults(t *testing.T) {
 	assert.Empty(t, factory.Base64Background)
 	assert.Empty(t, factory.Base64Header)
 	assert.Empty(t, factory.Base64Footer)
+	assert.Equal(t, DefaultTableStyle(), factory.TableStyle)
 }
 
 func TestRendererFactory_WithFontSizes(t *testing.T) {
@@ -80,3 +81,180 @@ func TestRendererFactory_Build_WithBase64Images(t *testing.T) {
 	assert.Equal(t, factory.FontSizes, renderer.FontSize)
 	assert.Equal(t, factory.ShowPageNumber, renderer.showPageNumber)
 }
+
+func TestRendererFactory_WithTableStyle(t *testing.T) {
+	style := TableStyle{
+		HeaderFill: &Color{R: 230, G: 230, B: 230},
+		Border:     TableBorderNone,
+	}
+	factory := NewRendererFactory().WithTableStyle(style)
+	assert.Equal(t, style, factory.TableStyle)
+
+	renderer, err := factory.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, style, renderer.TableStyle)
+}
+
+func TestRendererFactory_WithTableOfContents(t *testing.T) {
+	factory := NewRendererFactory()
+	assert.Nil(t, factory.TableOfContents)
+
+	factory.WithTableOfContents(TableOfContents{Title: "Contents", MaxLevel: 2})
+	assert.Equal(t, &TableOfContents{Title: "Contents", MaxLevel: 2}, factory.TableOfContents)
+
+	renderer, err := factory.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, factory.TableOfContents, renderer.TableOfContents)
+}
+
+func TestRendererFactory_WithBookmarks(t *testing.T) {
+	factory := NewRendererFactory().WithBookmarks(true)
+	assert.True(t, factory.Bookmarks)
+
+	renderer, err := factory.Build()
+	assert.NoError(t, err)
+	assert.True(t, renderer.Bookmarks)
+}
+
+func TestRendererFactory_WithPageNumberFormat(t *testing.T) {
+	factory := NewRendererFactory()
+	assert.Equal(t, DefaultPageNumberFormat(), factory.PageNumbers)
+
+	format := PageNumberFormat{Template: "{page} / {total}", Align: AlignCenter, Position: PageNumberHeader}
+	factory.WithPageNumberFormat(format)
+
+	renderer, err := factory.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, format, renderer.PageNumbers)
+}
+
+func TestRendererFactory_WithRunningTemplates(t *testing.T) {
+	factory := NewRendererFactory().
+		WithHeaderTemplate(`<p>{title}</p>`).
+		WithFooterTemplate(`<p>Page {page} of {total}</p>`)
+
+	renderer, err := factory.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, `<p>{title}</p>`, renderer.HeaderTemplate)
+	assert.Equal(t, `<p>Page {page} of {total}</p>`, renderer.FooterTemplate)
+}
+
+func TestRendererFactory_WithPagination(t *testing.T) {
+	factory := NewRendererFactory()
+	assert.Equal(t, DefaultPagination(), factory.Pagination)
+
+	renderer, err := factory.WithPagination(Pagination{Orphans: 3, Widows: 3}).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, Pagination{Orphans: 3, Widows: 3}, renderer.Pagination)
+}
+
+func TestRendererFactory_WithColumns(t *testing.T) {
+	renderer, err := NewRendererFactory().WithColumns(2, 18).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, renderer.Columns)
+	assert.Equal(t, 18.0, renderer.ColumnGap)
+}
+
+func TestRendererFactory_WithWatermark(t *testing.T) {
+	factory := NewRendererFactory().WithWatermark(Watermark{Text: "DRAFT", Angle: 45})
+
+	renderer, err := factory.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, &Watermark{Text: "DRAFT", Angle: 45}, renderer.Watermark)
+}
+
+func TestRendererFactory_WithImageOptions(t *testing.T) {
+	header := PageImageOptions{X: 50, Y: 10, Width: 495, Height: 60, Fit: ImageFitContain, SkipFirstPage: true}
+	footer := PageImageOptions{X: 50, Y: 790, Width: 495, Height: 40, Opacity: 0.5}
+	background := PageImageOptions{Width: 595.28, Height: 841.89, Fit: ImageFitCover, FirstPageOnly: true}
+
+	factory := NewRendererFactory()
+	assert.Equal(t, DefaultHeaderImageOptions(), factory.HeaderImageOptions)
+
+	renderer, err := factory.
+		WithHeaderImageOptions(header).
+		WithFooterImageOptions(footer).
+		WithBaseImageOptions(background).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, header, renderer.HeaderImageOptions)
+	assert.Equal(t, footer, renderer.FooterImageOptions)
+	assert.Equal(t, background, renderer.BackgroundImageOptions)
+}
+
+func TestRendererFactory_WithPageDecorator(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	banner := bannerDecorator{}
+	r, err := NewRendererFactory().WithPageDecorator(banner).Build()
+	assert.NoError(t, err)
+	assert.Len(t, r.Decorators, len(DefaultPageDecorators())+1)
+	assert.Equal(t, PageDecorator(banner), r.Decorators[len(r.Decorators)-1], "custom decorators run after the built-in ones")
+}
+
+func TestRendererFactory_WithMetadata(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	meta := Metadata{Title: "Report", Author: "Ops", Keywords: "weekly"}
+	r, err := NewRendererFactory().WithMetadata(meta).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, meta, r.Metadata)
+}
+
+func TestRendererFactory_WithProtection(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, err := NewRendererFactory().WithProtection("user", "owner", PermissionPrint).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, &Protection{UserPassword: "user", OwnerPassword: "owner", Permissions: PermissionPrint}, r.Protection)
+}
+
+func TestRendererFactory_WithPDFA(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, err := NewRendererFactory().WithPDFA(true).Build()
+	assert.NoError(t, err)
+	assert.True(t, r.PDFA)
+}
+
+func TestRendererFactory_WithTaggedPDF(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, err := NewRendererFactory().WithTaggedPDF(true).Build()
+	assert.NoError(t, err)
+	assert.True(t, r.Tagged)
+}
+
+func TestRendererFactory_WithTemplatePDF(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	path := writeLetterhead(t)
+
+	r, err := NewRendererFactory().WithTemplatePDF(path, 1).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, &TemplatePDF{Path: path, Page: 1}, r.TemplatePDF)
+
+	_, err = NewRendererFactory().WithTemplatePDF(path, 3).Build()
+	assert.ErrorContains(t, err, "has no page 3")
+}
+
+func TestRendererFactory_WithAttachment(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, err := NewRendererFactory().
+		WithAttachment("data.json", "application/json", []byte(`{}`)).
+		WithAttachment("data.csv", "text/csv", []byte("a,b")).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, []Attachment{
+		{Name: "data.json", MimeType: "application/json", Data: []byte(`{}`)},
+		{Name: "data.csv", MimeType: "text/csv", Data: []byte("a,b")},
+	}, r.Attachments)
+}
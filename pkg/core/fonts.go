@@ -43,6 +43,33 @@ func findFontPaths() (FontPaths, error) {
 	}, nil
 }
 
+// loadFontContainers parses the regular, bold, and italic fonts once, so that
+// they can be added to several PDFs without reading them again. Each style
+// needs its own container, as containers hold one font per family.
+func loadFontContainers() ([]*gopdf.FontContainer, error) {
+	fonts, err := findFontPaths()
+	if err != nil {
+		return nil, err
+	}
+	styles := []struct {
+		path  string
+		style int
+	}{
+		{fonts.Regular, gopdf.Regular},
+		{fonts.Bold, gopdf.Bold},
+		{fonts.Italic, gopdf.Italic},
+	}
+	containers := make([]*gopdf.FontContainer, 0, len(styles))
+	for _, s := range styles {
+		container := &gopdf.FontContainer{}
+		if err := container.AddTTFFontWithOption("Arial", s.path, gopdf.TtfOption{Style: s.style}); err != nil {
+			return nil, fmt.Errorf("font %s: %w", s.path, err)
+		}
+		containers = append(containers, container)
+	}
+	return containers, nil
+}
+
 // SetFont applies a dynamic font style (regular, bold, italic, or bold-italic) to the PDF context.
 //
 // It assumes the font family is named "Arial" (registered via AddTTFFontWithOption).
@@ -0,0 +1,362 @@
+// File: renderer/forms.go
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	// defaultFieldWidth sizes text fields and drop-downs without a width style.
+	defaultFieldWidth = 200.0
+
+	// defaultTextareaRows is the height, in lines, of a <textarea> without rows.
+	defaultTextareaRows = 3
+
+	// checkboxSize is the width and height of a checkbox.
+	checkboxSize = 12.0
+
+	// fieldFontSize is the font size of field values.
+	fieldFontSize = 10.0
+)
+
+// Field flags of interactive form fields (PDF 32000-1, 12.7.3 and 12.7.4).
+const (
+	fieldFlagReadOnly  = 1 << 0
+	fieldFlagRequired  = 1 << 1
+	fieldFlagMultiline = 1 << 12
+	fieldFlagCombo     = 1 << 17
+)
+
+// formField is a form field reserved by an <input>, <select>, or <textarea>
+// element.
+type formField struct {
+	kind          string     // "text", "checkbox", "select", or "textarea".
+	name          string     // Field name, from the name attribute.
+	value         string     // Initial value; for a select, the selected option's value.
+	options       [][]string // Export value and label of every option of a select.
+	checked       bool       // Whether a checkbox is checked.
+	flags         int        // Field flags from the readonly and required attributes.
+	page          int        // Page the field is on.
+	x, y          float64    // Top-left corner of the field.
+	width, height float64    // Size of the field.
+}
+
+// renderFormField reserves the box of a form field at the current position:
+// text inputs and drop-downs are one line high, a textarea is as high as its
+// rows attribute, and a checkbox is a small square. Text fields and drop-downs
+// are sized by the width style and all fields are placed by text-align. The
+// box becomes an interactive form field once the PDF is written.
+func (r *Renderer) renderFormField(n *html.Node) {
+	f := formField{kind: n.Data, name: getAttr(n, "name"), value: getAttr(n, "value")}
+	if n.Data == "input" {
+		switch t := strings.ToLower(getAttr(n, "type")); t {
+		case "", "text", "email", "tel", "number", "date":
+			f.kind = "text"
+		case "checkbox":
+			f.kind = t
+			f.checked = hasAttr(n, "checked")
+			if f.value == "" {
+				f.value = "Yes"
+			}
+		default:
+			// Buttons, radio buttons, and hidden inputs have no counterpart.
+			return
+		}
+	}
+	if hasAttr(n, "readonly") || hasAttr(n, "disabled") {
+		f.flags |= fieldFlagReadOnly
+	}
+	if hasAttr(n, "required") {
+		f.flags |= fieldFlagRequired
+	}
+
+	lineHeight := fieldFontSize * 1.6
+	f.width, f.height = min(defaultFieldWidth, r.width), lineHeight
+	switch f.kind {
+	case "checkbox":
+		f.width, f.height = checkboxSize, checkboxSize
+	case "textarea":
+		f.flags |= fieldFlagMultiline
+		f.value = strings.TrimPrefix(rawText(n), "\n")
+		rows := defaultTextareaRows
+		if v, err := strconv.Atoi(getAttr(n, "rows")); err == nil && v > 0 {
+			rows = v
+		}
+		f.height = float64(rows)*fieldFontSize*1.25 + 6
+	case "select":
+		f.flags |= fieldFlagCombo
+		f.options, f.value = selectOptions(n)
+	}
+	if f.kind != "checkbox" {
+		if v, ok := styleProperty(n, "width"); ok {
+			if w, ok := parseSize(v, r.width); ok && w > 0 {
+				f.width = min(w, r.width)
+			}
+		}
+	}
+	r.checkPageBreak(f.height)
+
+	f.x = r.left
+	switch textAlign(n) {
+	case AlignCenter:
+		f.x += (r.width - f.width) / 2
+	case AlignRight:
+		f.x += r.width - f.width
+	}
+	if !r.outOfFlow() {
+		if f.name == "" {
+			f.name = fmt.Sprintf("Field%d", len(r.formFields)+1)
+		}
+		f.page, f.y = r.pageNumber, r.y
+		r.formFields = append(r.formFields, f)
+	}
+	r.y += f.height + 6
+}
+
+// selectOptions returns the export value and label of every <option> of a
+// <select>, and the value of the selected one, or of the first.
+func selectOptions(n *html.Node) ([][]string, string) {
+	var options [][]string
+	selected := ""
+	for _, o := range findElements(n, "option") {
+		label := GetTextContent(o)
+		value := label
+		if hasAttr(o, "value") {
+			value = getAttr(o, "value")
+		}
+		options = append(options, []string{value, label})
+		if hasAttr(o, "selected") || len(options) == 1 {
+			selected = value
+		}
+	}
+	return options, selected
+}
+
+// rawText returns the text of n's children as written, without collapsing
+// whitespace, e.g. the content of a <textarea>.
+func rawText(n *html.Node) string {
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.TextNode {
+			b.WriteString(c.Data)
+		}
+	}
+	return b.String()
+}
+
+// addInputFields adds an interactive form field for every box reserved by a
+// form element to a rendered PDF. Each field comes with an appearance showing
+// its initial value in Helvetica, and the form asks viewers to regenerate
+// appearances, so that values outside Latin-1 display once edited. A checkbox
+// is on in the state named by its value, and same-named inputs become widgets
+// of one field.
+func addInputFields(pdf []byte, fields []formField) ([]byte, error) {
+	if bytes.Contains(pdf, []byte("/Encrypt")) {
+		return nil, errors.New("form fields cannot be added to password-protected PDFs")
+	}
+	u, err := newPDFUpdate(pdf)
+	if err != nil {
+		return nil, err
+	}
+	pages, err := u.pages()
+	if err != nil {
+		return nil, err
+	}
+
+	var inPages []formField
+	for _, f := range fields {
+		if f.page >= 1 && f.page <= len(pages) {
+			inPages = append(inPages, f)
+		}
+	}
+	groups, err := fieldGroups(inPages)
+	if err != nil {
+		return nil, err
+	}
+
+	frame := []byte("0.6 G 1 w")
+	var ids []int
+	for _, group := range groups {
+		field := fieldEntries(group)
+		parent := 0
+		if len(group) > 1 {
+			// Same-named inputs are widgets of one field, so that they share
+			// its value as they would in a submitted HTML form.
+			parent = u.add(nil)
+			ids = append(ids, parent)
+		}
+
+		var kids []int
+		for _, f := range group {
+			pageTop, err := u.pageTop(pages[f.page-1])
+			if err != nil {
+				return nil, err
+			}
+			top := pageTop - f.y
+			widget := []dictEntry{
+				{key: "Type", value: []byte("/Annot")},
+				{key: "Subtype", value: []byte("/Widget")},
+				{key: "Rect", value: []byte(fmt.Sprintf("[%.2f %.2f %.2f %.2f]", f.x, top-f.height, f.x+f.width, top))},
+				{key: "P", value: []byte(ref(pages[f.page-1]))},
+				{key: "F", value: []byte("4")},
+			}
+
+			switch f.kind {
+			case "checkbox":
+				on, state := pdfName(f.value), "/Off"
+				if f.checked {
+					state = on
+				}
+				yes := u.add(checkboxAppearance(f.width, f.height, true))
+				off := u.add(checkboxAppearance(f.width, f.height, false))
+				widget = dictSet(widget, "AS", state)
+				widget = dictSet(widget, "AP", fmt.Sprintf("<< /N << %s %s /Off %s >> >>", on, ref(yes), ref(off)))
+			default:
+				lines := strings.Split(f.value, "\n")
+				if f.kind != "textarea" {
+					lines = lines[:1]
+				}
+				for _, o := range f.options {
+					if o[0] == f.value {
+						lines = []string{o[1]}
+					}
+				}
+				appearance := u.add(formXObject(f.width, f.height, lines, frame))
+				widget = dictSet(widget, "AP", "<< /N "+ref(appearance)+" >>")
+			}
+
+			if parent != 0 {
+				widget = dictSet(widget, "Parent", ref(parent))
+			} else {
+				widget = append(field, widget...)
+			}
+			id := u.add(formatDict(widget))
+			if err := u.addAnnotation(pages[f.page-1], id); err != nil {
+				return nil, err
+			}
+			kids = append(kids, id)
+		}
+
+		if parent != 0 {
+			u.set(parent, formatDict(dictSet(field, "Kids", appendRefs(nil, kids...))))
+		} else {
+			ids = append(ids, kids...)
+		}
+	}
+	err = u.addFormFields(ids, func(form []dictEntry) []dictEntry {
+		form = dictSet(form, "DR", "<< /Font << /Helv << /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >> >> >>")
+		form = dictSet(form, "DA", fmt.Sprintf("(/Helv %g Tf 0 g)", float64(fieldFontSize)))
+		return dictSet(form, "NeedAppearances", "true")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return u.bytes(), nil
+}
+
+// fieldGroups groups form fields by name, in document order. Fields can only
+// share a name if they are of the same kind, and checkboxes sharing a name can
+// only be checked if they share a value, as a PDF field has a single value.
+// Other same-named fields with different values all show the first value.
+func fieldGroups(fields []formField) ([][]formField, error) {
+	var groups [][]formField
+	index := map[string]int{}
+	for _, f := range fields {
+		i, ok := index[f.name]
+		if !ok {
+			index[f.name] = len(groups)
+			groups = append(groups, []formField{f})
+			continue
+		}
+		first := groups[i][0]
+		if f.kind != first.kind {
+			return nil, fmt.Errorf("form fields named %q are of different kinds", f.name)
+		}
+		if f.kind != "checkbox" && f.value != first.value {
+			log.Printf("Form fields named %q have different values; using %q", f.name, first.value)
+			f.value = first.value
+		}
+		groups[i] = append(groups[i], f)
+	}
+
+	for _, group := range groups {
+		checked := ""
+		for _, f := range group {
+			if !f.checked {
+				continue
+			}
+			if checked != "" && f.value != checked {
+				return nil, fmt.Errorf("checkboxes named %q are checked with different values", f.name)
+			}
+			checked = f.value
+		}
+	}
+	return groups, nil
+}
+
+// fieldEntries returns the field dictionary entries shared by the widgets of a
+// group of same-named form fields: their name, type, flags, and value.
+func fieldEntries(group []formField) []dictEntry {
+	f := group[0]
+	field := []dictEntry{{key: "T", value: []byte(pdfTextString(f.name))}}
+	flags := 0
+	for _, g := range group {
+		flags |= g.flags
+	}
+	if flags != 0 {
+		field = dictSet(field, "Ff", strconv.Itoa(flags))
+	}
+
+	switch f.kind {
+	case "checkbox":
+		value := "/Off"
+		for _, g := range group {
+			if g.checked {
+				value = pdfName(g.value)
+			}
+		}
+		field = dictSet(field, "FT", "/Btn")
+		field = dictSet(field, "V", value)
+	default:
+		field = dictSet(field, "FT", "/Tx")
+		if f.kind == "select" {
+			var opts []string
+			for _, o := range f.options {
+				if o[0] == o[1] {
+					opts = append(opts, pdfTextString(o[1]))
+				} else {
+					opts = append(opts, "["+pdfTextString(o[0])+" "+pdfTextString(o[1])+"]")
+				}
+			}
+			field = dictSet(field, "FT", "/Ch")
+			field = dictSet(field, "Opt", "["+strings.Join(opts, " ")+"]")
+		}
+		field = dictSet(field, "DA", fmt.Sprintf("(/Helv %g Tf 0 g)", float64(fieldFontSize)))
+		if f.value != "" {
+			field = dictSet(field, "V", pdfTextString(f.value))
+		}
+	}
+	return field
+}
+
+// checkboxAppearance returns the appearance of a checkbox of the given size:
+// a frame, with a cross when it is checked.
+func checkboxAppearance(width, height float64, checked bool) []byte {
+	var content bytes.Buffer
+	fmt.Fprintf(&content, "q 0.6 G 1 w 0.5 0.5 %.2f %.2f re S Q\n", width-1, height-1)
+	if checked {
+		fmt.Fprintf(&content, "q 0 G 1.5 w 3 3 m %.2f %.2f l S 3 %.2f m %.2f 3 l S Q\n", width-3, height-3, height-3, width-3)
+	}
+	return streamObject([]dictEntry{
+		{key: "Type", value: []byte("/XObject")},
+		{key: "Subtype", value: []byte("/Form")},
+		{key: "BBox", value: []byte(fmt.Sprintf("[0 0 %.2f %.2f]", width, height))},
+	}, content.Bytes())
+}
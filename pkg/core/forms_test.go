@@ -0,0 +1,189 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// formWidgets returns the widget annotations of a PDF by field name.
+func formWidgets(t *testing.T, pdf []byte) map[string][]dictEntry {
+	u, err := newPDFUpdate(pdf)
+	require.NoError(t, err)
+	widgets := map[string][]dictEntry{}
+	for _, id := range u.ids() {
+		if dict, err := u.dict(id); err == nil && string(dictGet(dict, "Subtype")) == "/Widget" {
+			widgets[string(dictGet(dict, "T"))] = dict
+		}
+	}
+	return widgets
+}
+
+func TestRenderHTMLLikeToBuffer_FormFields(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+
+	buf, err := r.RenderHTMLLikeToBuffer(`
+		<input type="text" name="reviewer" value="Jane Doe" required style="width: 150px">
+		<input type="checkbox" name="approved" checked>
+		<select name="status"><option value="ok">Accepted</option><option selected>Rejected</option></select>
+		<textarea name="notes" rows="4">First line
+Second line</textarea>
+		<input type="checkbox" style="text-align: right">
+		<input type="hidden" name="ignored" value="x">`)
+	require.NoError(t, err)
+
+	widgets := formWidgets(t, buf.Bytes())
+	assert.Len(t, widgets, 5)
+
+	text := widgets[pdfTextString("reviewer")]
+	assert.Equal(t, "/Tx", string(dictGet(text, "FT")))
+	assert.Equal(t, pdfTextString("Jane Doe"), string(dictGet(text, "V")))
+	assert.Equal(t, "2", string(dictGet(text, "Ff")), "required")
+	assert.Equal(t, "[50.00 776.00 200.00 792.00]", string(dictGet(text, "Rect")))
+
+	checkbox := widgets[pdfTextString("approved")]
+	assert.Equal(t, "/Btn", string(dictGet(checkbox, "FT")))
+	assert.Equal(t, "/Yes", string(dictGet(checkbox, "V")))
+	assert.Equal(t, "/Yes", string(dictGet(checkbox, "AS")))
+
+	choice := widgets[pdfTextString("status")]
+	assert.Equal(t, "/Ch", string(dictGet(choice, "FT")))
+	assert.Equal(t, pdfTextString("Rejected"), string(dictGet(choice, "V")))
+	assert.Equal(t, "[["+pdfTextString("ok")+" "+pdfTextString("Accepted")+"] "+pdfTextString("Rejected")+"]", string(dictGet(choice, "Opt")))
+	assert.Equal(t, "131072", string(dictGet(choice, "Ff")), "combo box")
+
+	notes := widgets[pdfTextString("notes")]
+	assert.Equal(t, pdfTextString("First line\nSecond line"), string(dictGet(notes, "V")))
+	assert.Equal(t, "4096", string(dictGet(notes, "Ff")), "multiline")
+
+	unnamed := widgets[pdfTextString("Field5")]
+	assert.Equal(t, "/Off", string(dictGet(unnamed, "V")))
+	assert.Equal(t, "[533.00", string(dictGet(unnamed, "Rect")[:7]), "right-aligned")
+
+	u, err := newPDFUpdate(buf.Bytes())
+	require.NoError(t, err)
+	rootID, _ := u.root()
+	catalog, err := u.dict(rootID)
+	require.NoError(t, err)
+	form, err := parseDict(dictGet(catalog, "AcroForm"))
+	require.NoError(t, err)
+	assert.Len(t, arrayRefs(dictGet(form, "Fields")), 5)
+	assert.Equal(t, "true", string(dictGet(form, "NeedAppearances")))
+}
+
+func TestRenderHTMLLikeToBuffer_FormFieldsWithSignature(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+
+	buf, err := r.RenderHTMLLikeToBuffer(`<input name="name"><signature name="approver"></signature>`)
+	require.NoError(t, err)
+
+	widgets := formWidgets(t, buf.Bytes())
+	assert.Contains(t, widgets, pdfTextString("name"))
+	assert.Contains(t, widgets, pdfTextString("approver"))
+}
+
+func TestRenderHTMLLikeToBuffer_FormFieldsRejectPDFA(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	r.PDFA = true
+
+	_, err := r.RenderHTMLLikeToBuffer(`<input name="name">`)
+	assert.ErrorIs(t, err, ErrPDFANonCompliant)
+}
+
+func TestRenderHTMLLikeToBuffer_FormFieldsSharingName(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+
+	buf, err := r.RenderHTMLLikeToBuffer(`
+		<input type="checkbox" name="severity" value="low">
+		<input type="checkbox" name="severity" value="high risk" checked>
+		<input type="checkbox" name="agree" value="accepted">`)
+	require.NoError(t, err)
+
+	u, err := newPDFUpdate(buf.Bytes())
+	require.NoError(t, err)
+	rootID, _ := u.root()
+	catalog, err := u.dict(rootID)
+	require.NoError(t, err)
+	form, err := parseDict(dictGet(catalog, "AcroForm"))
+	require.NoError(t, err)
+	fields := arrayRefs(dictGet(form, "Fields"))
+	require.Len(t, fields, 2)
+
+	severity, err := u.dict(fields[0])
+	require.NoError(t, err)
+	assert.Equal(t, pdfTextString("severity"), string(dictGet(severity, "T")))
+	assert.Equal(t, "/Btn", string(dictGet(severity, "FT")))
+	assert.Equal(t, "/high#20risk", string(dictGet(severity, "V")))
+	kids := arrayRefs(dictGet(severity, "Kids"))
+	require.Len(t, kids, 2)
+
+	var states []string
+	for _, id := range kids {
+		widget, err := u.dict(id)
+		require.NoError(t, err)
+		assert.Nil(t, dictGet(widget, "T"), "the name is on the parent field")
+		assert.Equal(t, ref(fields[0]), string(dictGet(widget, "Parent")))
+		states = append(states, string(dictGet(widget, "AS")))
+	}
+	assert.Equal(t, []string{"/Off", "/high#20risk"}, states)
+
+	agree, err := u.dict(fields[1])
+	require.NoError(t, err)
+	assert.Equal(t, "/Off", string(dictGet(agree, "V")))
+	assert.Contains(t, string(dictGet(agree, "AP")), "/accepted ")
+}
+
+func TestRenderHTMLLikeToBuffer_FormFieldsConflictingNames(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	tests := map[string]string{
+		"kinds":      `<input name="a"><textarea name="a"></textarea>`,
+		"checkboxes": `<input type="checkbox" name="a" value="x" checked><input type="checkbox" name="a" value="y" checked>`,
+	}
+	for name, doc := range tests {
+		t.Run(name, func(t *testing.T) {
+			r, _ := NewRenderer(defaultFontSizes(), true)
+			_, err := r.RenderHTMLLikeToBuffer(doc)
+			assert.ErrorContains(t, err, `named "a"`)
+		})
+	}
+}
+
+func TestRenderHTMLLikeToBuffer_FormFieldsSharingNameWithDifferentValues(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+
+	buf, err := r.RenderHTMLLikeToBuffer(`<input name="a" value="x"><input name="a" value="y">`)
+	require.NoError(t, err)
+
+	u, err := newPDFUpdate(buf.Bytes())
+	require.NoError(t, err)
+	rootID, _ := u.root()
+	catalog, err := u.dict(rootID)
+	require.NoError(t, err)
+	form, err := parseDict(dictGet(catalog, "AcroForm"))
+	require.NoError(t, err)
+	fields := arrayRefs(dictGet(form, "Fields"))
+	require.Len(t, fields, 1)
+
+	field, err := u.dict(fields[0])
+	require.NoError(t, err)
+	assert.Equal(t, pdfTextString("x"), string(dictGet(field, "V")), "the first value is used")
+	assert.Len(t, arrayRefs(dictGet(field, "Kids")), 2)
+}
@@ -23,52 +23,172 @@ const (
 	AlignLeft   Alignment = "left"
 	AlignCenter Alignment = "center"
 	AlignRight  Alignment = "right"
+
+	// AlignJustify stretches inter-word spacing so that every line except the
+	// last one of a block fills the full width. It applies to text only.
+	AlignJustify Alignment = "justify"
 )
 
 // GetTextContent extracts and returns all visible text from an HTML node and its children.
+// Whitespace is collapsed the way a browser would: runs of spaces, tabs, and
+// newlines become a single space, and leading/trailing whitespace is removed,
+// so "<p>Hello <strong>world</strong>!</p>" yields "Hello world!".
 func GetTextContent(n *html.Node) string {
 	var buf bytes.Buffer
 	walkText(n, &buf)
-	return buf.String()
+	return strings.Join(strings.Fields(buf.String()), " ")
 }
 
 // walkText recursively walks the HTML tree and collects text from TextNode elements.
+// Text is written unmodified; <br> elements are written as a space.
 func walkText(n *html.Node, buf io.Writer) {
 	if n.Type == html.TextNode {
-		_, err := buf.Write([]byte(strings.TrimSpace(n.Data)))
+		_, err := buf.Write([]byte(n.Data))
 		if err != nil {
 			return
 		}
 	}
+	if n.Type == html.ElementNode && n.Data == "br" {
+		_, _ = buf.Write([]byte(" "))
+	}
 	for c := n.FirstChild; c != nil; c = c.NextSibling {
 		walkText(c, buf)
 	}
 }
 
-// GetStyledTextChunks parses an HTML node tree and returns chunks of text with bold/italic annotations.
+// GetStyledTextChunks parses an HTML node tree and returns chunks of styled text.
+// Besides bold/italic it tracks text color (style="color: ..." or <font color>),
+// link targets (<a href>), forced line breaks (<br>), and inline images (<img>).
+//
+// Whitespace follows CSS "white-space: normal" rules: every whitespace run is
+// collapsed to a single space, a space directly following another space (even
+// across element boundaries) is dropped, and spaces at the start and end of the
+// block or around a <br> are removed. Meaningful spaces are kept in the chunk
+// text, so "<p>Hello <strong>world</strong>!</p>" yields "Hello ", "world", "!".
 func GetStyledTextChunks(n *html.Node) []TextChunk {
+	return styledTextChunks(n, nil)
+}
+
+// styledTextChunks is GetStyledTextChunks leaving out the descendants of n for
+// which skip returns true, e.g. the nested lists of a list item.
+func styledTextChunks(n *html.Node, skip func(*html.Node) bool) []TextChunk {
 	var chunks []TextChunk
+	afterSpace := true // at the start of the block, leading whitespace is dropped
 
-	var walk func(node *html.Node, italic, bold bool)
-	walk = func(node *html.Node, italic, bold bool) {
+	var walk func(node *html.Node, style TextChunk)
+	walk = func(node *html.Node, style TextChunk) {
 		if node.Type == html.TextNode {
-			text := strings.TrimSpace(node.Data)
+			text := collapseWhitespace(node.Data)
+			if afterSpace {
+				text = strings.TrimLeft(text, " ")
+			}
 			if text != "" {
-				chunks = append(chunks, TextChunk{Text: text, Italic: italic, Bold: bold})
+				chunk := style
+				chunk.Text = text
+				chunks = append(chunks, chunk)
+				afterSpace = strings.HasSuffix(text, " ")
 			}
 		} else if node.Type == html.ElementNode {
-			newItalic := italic || node.Data == "em" || node.Data == "i"
-			newBold := bold || node.Data == "strong" || node.Data == "b"
+			switch node.Data {
+			case "br":
+				chunks = trimTrailingSpace(chunks)
+				chunks = append(chunks, TextChunk{LineBreak: true})
+				afterSpace = true
+				return
+			case "img":
+				chunk := style
+				chunk.Image = getAttr(node, "src")
+				chunk.ImageW, chunk.ImageH = inlineImageSize(node)
+				if chunk.Image != "" {
+					chunks = append(chunks, chunk)
+					afterSpace = false
+				}
+				return
+			case "em", "i":
+				style.Italic = true
+			case "strong", "b":
+				style.Bold = true
+			case "a":
+				if href := getAttr(node, "href"); href != "" {
+					// Links do not inherit the surrounding text color; they use
+					// the renderer's LinkStyle unless they set a color themselves.
+					style.Link = href
+					style.Color = nil
+				}
+			case "font":
+				if c, ok := parseColor(getAttr(node, "color")); ok {
+					style.Color = &c
+				}
+			}
+			if val, ok := styleProperty(node, "color"); ok {
+				if c, ok := parseColor(val); ok {
+					style.Color = &c
+				}
+			}
 			for c := node.FirstChild; c != nil; c = c.NextSibling {
-				walk(c, newItalic, newBold)
+				if skip == nil || !skip(c) {
+					walk(c, style)
+				}
 			}
 		}
 	}
 
-	walk(n, false, false)
+	walk(n, TextChunk{})
+	return trimTrailingSpace(chunks)
+}
+
+// collapseWhitespace replaces every run of whitespace characters with a single space.
+func collapseWhitespace(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range s {
+		if r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f' {
+			if !space {
+				b.WriteByte(' ')
+			}
+			space = true
+			continue
+		}
+		b.WriteRune(r)
+		space = false
+	}
+	return b.String()
+}
+
+// trimTrailingSpace removes the trailing space of the last text chunk, dropping
+// the chunk entirely if nothing else is left in it.
+func trimTrailingSpace(chunks []TextChunk) []TextChunk {
+	if len(chunks) == 0 {
+		return chunks
+	}
+	last := &chunks[len(chunks)-1]
+	if last.LineBreak || last.Image != "" {
+		return chunks
+	}
+	last.Text = strings.TrimRight(last.Text, " ")
+	if last.Text == "" {
+		return chunks[:len(chunks)-1]
+	}
 	return chunks
 }
 
+// inlineImageSize reads the width and height of an inline <img> from its
+// width/height attributes or inline style. Missing dimensions are returned as zero.
+func inlineImageSize(n *html.Node) (float64, float64) {
+	size := func(name string) float64 {
+		if val, ok := styleProperty(n, name); ok {
+			if v, ok := parseLength(val); ok {
+				return v
+			}
+		}
+		if v, ok := parseLength(getAttr(n, name)); ok {
+			return v
+		}
+		return 0
+	}
+	return size("width"), size("height")
+}
+
 // WrapLogoAsHTML returns an HTML <img> tag wrapped in a div with the specified alignment.
 func WrapLogoAsHTML(logoBase64 string, align Alignment) template.HTML {
 	if logoBase64 == "" {
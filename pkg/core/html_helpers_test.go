@@ -43,21 +43,39 @@ func TestGetStyledTextChunks_BoldItalic(t *testing.T) {
 	chunks := GetStyledTextChunks(pNode)
 	assert.GreaterOrEqual(t, len(chunks), 4)
 
-	// Optionally check exact text/style
-	assert.Equal(t, "This is", chunks[0].Text)
+	// Optionally check exact text/style; spaces between inline elements are preserved
+	assert.Equal(t, "This is ", chunks[0].Text)
 	assert.False(t, chunks[0].Bold)
 	assert.False(t, chunks[0].Italic)
 
 	assert.Equal(t, "bold", chunks[1].Text)
 	assert.True(t, chunks[1].Bold)
 
-	assert.Equal(t, "and", chunks[2].Text)
+	assert.Equal(t, " and ", chunks[2].Text)
 	assert.False(t, chunks[2].Bold)
 
 	assert.Equal(t, "italic", chunks[3].Text)
 	assert.True(t, chunks[3].Italic)
 }
 
+func TestGetTextContent_CollapsesWhitespace(t *testing.T) {
+	node, _ := html.Parse(strings.NewReader("<p>\n  Hello <strong>world</strong>!\n\tSecond<br>line  </p>"))
+	assert.Equal(t, "Hello world! Second line", GetTextContent(node))
+}
+
+func TestGetStyledTextChunks_CollapsesWhitespace(t *testing.T) {
+	doc, _ := html.Parse(strings.NewReader("<p>\n  Hello <strong> world </strong> !<br>  next\n</p>"))
+	chunks := GetStyledTextChunks(findNode(doc, "p"))
+
+	assert.Len(t, chunks, 5)
+	assert.Equal(t, "Hello ", chunks[0].Text)
+	assert.Equal(t, "world ", chunks[1].Text)
+	assert.True(t, chunks[1].Bold)
+	assert.Equal(t, "!", chunks[2].Text)
+	assert.True(t, chunks[3].LineBreak)
+	assert.Equal(t, "next", chunks[4].Text)
+}
+
 func TestWrapLogoAsHTML_GeneratesValidHTML(t *testing.T) {
 	html := WrapLogoAsHTML("data:image/png;base64,fake", AlignCenter)
 	assert.Contains(t, string(html), "text-align: center")
@@ -165,3 +183,28 @@ func TestLoadImageBase64_UnsupportedExtensionPanics(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, string(output), "Unsupported image type")
 }
+
+func TestGetStyledTextChunks_ColorLinksBreaksAndImages(t *testing.T) {
+	htmlStr := `<td><span style="color: #ff0000">Failed</span><br><a href="https://example.com">details</a>` +
+		`<img src="data:image/png;base64,xyz" width="10" height="8"><font color="blue">ok</font></td>`
+	doc, _ := html.Parse(strings.NewReader(`<table><tr>` + htmlStr + `</tr></table>`))
+
+	chunks := GetStyledTextChunks(findNode(doc, "td"))
+	assert.Len(t, chunks, 5)
+
+	assert.Equal(t, "Failed", chunks[0].Text)
+	assert.Equal(t, &Color{R: 255}, chunks[0].Color)
+
+	assert.True(t, chunks[1].LineBreak)
+
+	assert.Equal(t, "details", chunks[2].Text)
+	assert.Equal(t, "https://example.com", chunks[2].Link)
+	assert.Nil(t, chunks[2].Color)
+
+	assert.Equal(t, "data:image/png;base64,xyz", chunks[3].Image)
+	assert.Equal(t, 10.0, chunks[3].ImageW)
+	assert.Equal(t, 8.0, chunks[3].ImageH)
+
+	assert.Equal(t, "ok", chunks[4].Text)
+	assert.Equal(t, &Color{B: 255}, chunks[4].Color)
+}
@@ -3,6 +3,7 @@ package core
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"golang.org/x/net/html"
 	"os"
@@ -19,10 +20,56 @@ func (r *Renderer) RenderHTMLLikeToBuffer(content string) (*bytes.Buffer, error)
 		return nil, fmt.Errorf("failed to parse HTML: %w", err)
 	}
 
+	if r.PDFA {
+		if err := r.checkPDFA(); err != nil {
+			return nil, err
+		}
+	}
+	if err := r.checkAttachments(); err != nil {
+		return nil, err
+	}
+	if r.Tagged && r.Protection != nil {
+		return nil, errors.New("tagged PDF output cannot be combined with password protection")
+	}
+	if r.Protection != nil {
+		if err := r.protect(); err != nil {
+			return nil, err
+		}
+	}
+
 	r.extractFooterText(doc)
-	r.walk(doc)
-	r.drawFooterAtFixedPosition()
-	r.drawTimestamp()
+	r.extractRunningBlocks(doc)
+	// Later pages are decorated as they are started in flushPage.
+	r.startPage()
+
+	// A table of contents is built in two passes: its entries are collected from
+	// the document tree before layout, and their page numbers are filled in once
+	// the whole document has been laid out.
+	tocElement := hasTOCElement(doc)
+	if r.TableOfContents != nil || tocElement {
+		r.collectTOCEntries(doc)
+	}
+	r.collectAnchorIDs(doc)
+	if r.TableOfContents != nil && !tocElement {
+		r.renderTOC()
+		r.flushPage()
+	}
+
+	if r.Columns > 1 {
+		gap := r.ColumnGap
+		if gap <= 0 {
+			gap = r.FontSize.P
+		}
+		r.renderColumns(doc, r.Columns, gap)
+	} else {
+		r.walk(doc)
+	}
+	r.resolveAnchors()
+	r.drawRunningBlocks()
+	r.finishPages()
+	r.fillTOCPageNumbers()
+	r.linkOutlines()
+	meta := r.applyMetadata()
 
 	// Write to a temporary file
 	tmpFile, err := os.CreateTemp("", "report_*.pdf")
@@ -50,6 +97,40 @@ func (r *Renderer) RenderHTMLLikeToBuffer(content string) (*bytes.Buffer, error)
 		return nil, fmt.Errorf("failed to read temp PDF: %w", err)
 	}
 
+	pdfBytes = addKeywords(pdfBytes, meta.Keywords)
+	if len(r.outlineRoots) > 0 {
+		if pdfBytes, err = countOutlines(pdfBytes, r.outlineRoots); err != nil {
+			return nil, err
+		}
+	}
+	if r.Tagged {
+		if pdfBytes, err = tagPDF(pdfBytes, taggedLayout{elems: r.structElems, links: r.linkTags, markers: r.markers}, documentLanguage(doc)); err != nil {
+			return nil, err
+		}
+	}
+	if len(r.Attachments) > 0 {
+		if pdfBytes, err = addAttachments(pdfBytes, r.Attachments, meta.CreationDate); err != nil {
+			return nil, err
+		}
+	}
+	if len(r.formFields) > 0 {
+		if r.PDFA {
+			return nil, fmt.Errorf("%w: form fields need appearances regenerated by the viewer", ErrPDFANonCompliant)
+		}
+		if pdfBytes, err = addInputFields(pdfBytes, r.formFields); err != nil {
+			return nil, err
+		}
+	}
+	if len(r.signatureFields) > 0 {
+		if pdfBytes, err = addSignatureFields(pdfBytes, r.signatureFields); err != nil {
+			return nil, err
+		}
+	}
+	if r.PDFA {
+		if pdfBytes, err = makePDFA(pdfBytes, meta); err != nil {
+			return nil, err
+		}
+	}
 	return bytes.NewBuffer(pdfBytes), nil
 }
 
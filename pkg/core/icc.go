@@ -0,0 +1,88 @@
+// File: renderer/icc.go
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+)
+
+// srgbICCProfile builds a version 2 ICC display profile for the sRGB color
+// space (IEC 61966-2.1): the D50-adapted sRGB primaries and the sRGB tone curve
+// sampled at 1024 points. It is embedded as the output intent of PDF/A
+// documents, so that their DeviceRGB colors have a defined meaning.
+func srgbICCProfile() []byte {
+	xyz := func(x, y, z float64) []byte {
+		b := []byte("XYZ \x00\x00\x00\x00")
+		for _, v := range []float64{x, y, z} {
+			b = binary.BigEndian.AppendUint32(b, uint32(int32(math.Round(v*65536))))
+		}
+		return b
+	}
+
+	desc := []byte("desc\x00\x00\x00\x00")
+	name := "sRGB IEC61966-2.1"
+	desc = binary.BigEndian.AppendUint32(desc, uint32(len(name)+1))
+	desc = append(desc, name...)
+	desc = append(desc, 0)
+	desc = append(desc, make([]byte, 4+4+2+1+67)...) // Empty Unicode and ScriptCode descriptions.
+
+	curve := []byte("curv\x00\x00\x00\x00")
+	const points = 1024
+	curve = binary.BigEndian.AppendUint32(curve, points)
+	for i := 0; i < points; i++ {
+		v := float64(i) / (points - 1)
+		if v <= 0.04045 {
+			v /= 12.92
+		} else {
+			v = math.Pow((v+0.055)/1.055, 2.4)
+		}
+		curve = binary.BigEndian.AppendUint16(curve, uint16(math.Round(v*65535)))
+	}
+
+	tags := []struct {
+		sig  string
+		data []byte
+	}{
+		{"desc", desc},
+		{"cprt", append([]byte("text\x00\x00\x00\x00No copyright, use freely"), 0)},
+		{"wtpt", xyz(0.9642, 1.0, 0.8249)},
+		{"rXYZ", xyz(0.4360747, 0.2225045, 0.0139322)},
+		{"gXYZ", xyz(0.3850649, 0.7168786, 0.0971045)},
+		{"bXYZ", xyz(0.1430804, 0.0606169, 0.7141733)},
+		{"rTRC", curve},
+		{"gTRC", curve},
+		{"bTRC", curve},
+	}
+
+	// Tag data follows the 128-byte header and the tag table, 4-byte aligned.
+	var table, data bytes.Buffer
+	offset := 128 + 4 + 12*len(tags)
+	binary.Write(&table, binary.BigEndian, uint32(len(tags)))
+	for _, tag := range tags {
+		for data.Len()%4 != 0 {
+			data.WriteByte(0)
+		}
+		table.WriteString(tag.sig)
+		binary.Write(&table, binary.BigEndian, uint32(offset+data.Len()))
+		binary.Write(&table, binary.BigEndian, uint32(len(tag.data)))
+		data.Write(tag.data)
+	}
+	size := offset + data.Len()
+
+	header := make([]byte, 0, 128)
+	header = binary.BigEndian.AppendUint32(header, uint32(size))
+	header = append(header, 0, 0, 0, 0)       // Preferred CMM.
+	header = append(header, 0x02, 0x10, 0, 0) // Version 2.1.
+	header = append(header, "mntrRGB XYZ "...)
+	for _, v := range []uint16{2024, 1, 1, 0, 0, 0} { // Creation date.
+		header = binary.BigEndian.AppendUint16(header, v)
+	}
+	header = append(header, "acsp"...)
+	header = append(header, make([]byte, 4+4+4+4+8+4)...) // Platform, flags, device, attributes, intent.
+	header = append(header, xyz(0.9642, 1.0, 0.8249)[8:]...)
+	header = append(header, make([]byte, 128-len(header))...)
+
+	profile := append(header, table.Bytes()...)
+	return append(profile, data.Bytes()...)
+}
@@ -50,7 +50,7 @@ func saveBase64ImageToTempFile(dataURI string) (string, error) {
 		ext = ".img"
 	}
 
-	decoded, err := base64.StdEncoding.DecodeString(parts[1])
+	decoded, err := decodeDataURI(dataURI)
 	if err != nil {
 		return "", err
 	}
@@ -67,3 +67,30 @@ func saveBase64ImageToTempFile(dataURI string) (string, error) {
 
 	return tmpfile.Name(), nil
 }
+
+// decodeDataURI returns the decoded base64 payload of a data URI.
+func decodeDataURI(dataURI string) ([]byte, error) {
+	_, data, ok := strings.Cut(dataURI, ",")
+	if !ok {
+		return nil, fmt.Errorf("invalid data URI")
+	}
+	return base64.StdEncoding.DecodeString(data)
+}
+
+// resolveImageSource turns an <img> src value into a local file path that gopdf
+// can draw. "file://" sources are used as-is; "data:image/..." URIs are decoded
+// to a temporary file which the returned cleanup function removes.
+func resolveImageSource(src string) (string, func(), error) {
+	switch {
+	case strings.HasPrefix(src, "file://"):
+		return strings.TrimPrefix(src, "file://"), func() {}, nil
+	case strings.HasPrefix(src, "data:image/"):
+		path, err := saveBase64ImageToTempFile(src)
+		if err != nil {
+			return "", nil, err
+		}
+		return path, func() { _ = os.Remove(path) }, nil
+	default:
+		return "", nil, fmt.Errorf("unsupported image source: %.32s", src)
+	}
+}
@@ -0,0 +1,326 @@
+// File: renderer/inline.go
+package core
+
+import (
+	"log"
+	"strings"
+
+	"github.com/signintech/gopdf"
+)
+
+// inlineRun is a piece of inline content that is drawn with a single style:
+// either a run of text or an inline image.
+type inlineRun struct {
+	chunk TextChunk // Text (or image source) and style of the run.
+	width float64   // Measured width of the run in points.
+}
+
+// textLine is one laid-out line of inline content.
+type textLine struct {
+	runs        []inlineRun
+	width       float64 // Total width of all runs.
+	height      float64 // Line height, grown to fit any inline image.
+	baseHeight  float64 // Height of a line of plain text; text sits at the bottom of taller lines.
+	forcedBreak bool    // Whether the line was ended by a <br> rather than by wrapping.
+}
+
+// spaceCount returns the number of inter-word spaces in the line's text runs.
+func (l textLine) spaceCount() int {
+	count := 0
+	for _, run := range l.runs {
+		if run.chunk.Image == "" {
+			count += strings.Count(run.chunk.Text, " ")
+		}
+	}
+	return count
+}
+
+// sameStyle reports whether two chunks are drawn with the same font, color, and link.
+func (c TextChunk) sameStyle(o TextChunk) bool {
+	if c.Italic != o.Italic || c.Bold != o.Bold || c.Link != o.Link {
+		return false
+	}
+	if c.Color == nil || o.Color == nil {
+		return c.Color == o.Color
+	}
+	return *c.Color == *o.Color
+}
+
+// applyFont selects the font face for a chunk's style at the given size and
+// sets the text color. Bold italic falls back to bold, since only regular,
+// bold, and italic faces are registered. Link text without an explicit color
+// is drawn with the renderer's LinkStyle.
+func (r *Renderer) applyFont(chunk TextChunk, size float64) {
+	style := ""
+	if chunk.Bold {
+		style += "B"
+	}
+	if chunk.Italic && !chunk.Bold {
+		style += "I"
+	}
+	if chunk.Link != "" && r.LinkStyle.Underline {
+		style += "U"
+	}
+	_ = r.pdf.SetFont("Arial", style, size)
+
+	switch {
+	case chunk.Color != nil:
+		r.pdf.SetTextColor(chunk.Color.R, chunk.Color.G, chunk.Color.B)
+	case chunk.Link != "":
+		r.pdf.SetTextColor(r.LinkStyle.Color.R, r.LinkStyle.Color.G, r.LinkStyle.Color.B)
+	default:
+		r.pdf.SetTextColor(0, 0, 0)
+	}
+}
+
+// inlineWord is an unbreakable sequence of inline content. A word may span
+// several chunks (e.g. "<b>world</b>!"), so it is kept as styled segments.
+type inlineWord struct {
+	segments    []inlineRun
+	width       float64
+	spaceBefore bool      // Whether collapsed whitespace precedes the word.
+	spaceStyle  TextChunk // Style of that whitespace, used to measure and draw it.
+	lineBreak   bool      // Whether this entry is a forced line break rather than a word.
+}
+
+// splitWords tokenizes styled chunks into words at whitespace boundaries,
+// measuring each segment in its own font.
+func (r *Renderer) splitWords(chunks []TextChunk, fontSize, lineHeight float64) []inlineWord {
+	var words []inlineWord
+	var current *inlineWord
+	pendingSpace := false
+	var spaceStyle TextChunk
+
+	appendSegment := func(seg inlineRun) {
+		if current == nil || pendingSpace {
+			words = append(words, inlineWord{spaceBefore: pendingSpace, spaceStyle: spaceStyle})
+			current = &words[len(words)-1]
+			pendingSpace = false
+		}
+		current.segments = append(current.segments, seg)
+		current.width += seg.width
+	}
+
+	for _, chunk := range chunks {
+		switch {
+		case chunk.LineBreak:
+			words = append(words, inlineWord{lineBreak: true})
+			current = nil
+			pendingSpace = false
+			continue
+		case chunk.Image != "":
+			if chunk.ImageW == 0 {
+				chunk.ImageW = lineHeight
+			}
+			if chunk.ImageH == 0 {
+				chunk.ImageH = lineHeight
+			}
+			appendSegment(inlineRun{chunk: chunk, width: chunk.ImageW})
+			continue
+		}
+
+		r.applyFont(chunk, fontSize)
+		for i, part := range strings.Split(chunk.Text, " ") {
+			if i > 0 {
+				pendingSpace = true
+				spaceStyle = chunk
+				spaceStyle.Text = " "
+			}
+			if part == "" {
+				continue
+			}
+			seg := chunk
+			seg.Text = part
+			width, _ := r.pdf.MeasureTextWidth(part)
+			appendSegment(inlineRun{chunk: seg, width: width})
+		}
+	}
+	return words
+}
+
+// layoutChunks breaks styled chunks into lines no wider than maxWidth.
+// Runs of different styles share a line, each advancing X by its own width;
+// lines are wrapped only at whitespace when the next word does not fit, and
+// a <br> always ends the current line. A single word wider than maxWidth is
+// moved to its own line and broken between characters instead of overflowing.
+func (r *Renderer) layoutChunks(chunks []TextChunk, maxWidth, fontSize, lineHeight float64) []textLine {
+	var lines []textLine
+	line := textLine{height: lineHeight, baseHeight: lineHeight}
+
+	addRun := func(run inlineRun) {
+		if n := len(line.runs); n > 0 && run.chunk.Image == "" {
+			last := &line.runs[n-1]
+			if last.chunk.Image == "" && last.chunk.sameStyle(run.chunk) {
+				last.chunk.Text += run.chunk.Text
+				last.width += run.width
+				line.width += run.width
+				return
+			}
+		}
+		line.runs = append(line.runs, run)
+		line.width += run.width
+		if run.chunk.Image != "" {
+			line.height = max(line.height, run.chunk.ImageH+2)
+		}
+	}
+	pushLine := func() {
+		lines = append(lines, line)
+		line = textLine{height: lineHeight, baseHeight: lineHeight}
+	}
+
+	for _, word := range r.splitWords(chunks, fontSize, lineHeight) {
+		if word.lineBreak {
+			line.forcedBreak = true
+			pushLine()
+			continue
+		}
+
+		spaceWidth := 0.0
+		if word.spaceBefore && len(line.runs) > 0 {
+			r.applyFont(word.spaceStyle, fontSize)
+			spaceWidth, _ = r.pdf.MeasureTextWidth(" ")
+		}
+		if len(line.runs) > 0 && line.width+spaceWidth+word.width > maxWidth {
+			pushLine()
+			spaceWidth = 0
+		}
+		if spaceWidth > 0 {
+			addRun(inlineRun{chunk: word.spaceStyle, width: spaceWidth})
+		}
+		if word.width <= maxWidth {
+			for _, seg := range word.segments {
+				addRun(seg)
+			}
+			continue
+		}
+
+		for _, seg := range word.segments {
+			if seg.chunk.Image != "" {
+				if len(line.runs) > 0 && line.width+seg.width > maxWidth {
+					pushLine()
+				}
+				addRun(seg)
+				continue
+			}
+			r.applyFont(seg.chunk, fontSize)
+			part, partWidth := "", 0.0
+			for _, ch := range seg.chunk.Text {
+				test := part + string(ch)
+				width, _ := r.pdf.MeasureTextWidth(test)
+				if line.width+width > maxWidth && (part != "" || len(line.runs) > 0) {
+					if part != "" {
+						piece := seg.chunk
+						piece.Text = part
+						addRun(inlineRun{chunk: piece, width: partWidth})
+					}
+					pushLine()
+					part = string(ch)
+					partWidth, _ = r.pdf.MeasureTextWidth(part)
+					continue
+				}
+				part, partWidth = test, width
+			}
+			if part != "" {
+				piece := seg.chunk
+				piece.Text = part
+				addRun(inlineRun{chunk: piece, width: partWidth})
+			}
+		}
+	}
+	if len(line.runs) > 0 {
+		pushLine()
+	}
+	return lines
+}
+
+// drawAlignedLine draws a line inside a box of the given width using the
+// requested alignment. Justified lines stretch their inter-word spaces to fill
+// the width, except the last line of a block and lines ended by a <br>, which
+// are left-aligned.
+func (r *Renderer) drawAlignedLine(line textLine, x, y, width, fontSize float64, align Alignment, last bool) {
+	wordSpacing := 0.0
+	switch align {
+	case AlignCenter:
+		x += (width - line.width) / 2
+	case AlignRight:
+		x += width - line.width
+	case AlignJustify:
+		if !last && !line.forcedBreak && line.width < width {
+			if gaps := line.spaceCount(); gaps > 0 {
+				wordSpacing = (width - line.width) / float64(gaps)
+			}
+		}
+	}
+	r.drawTextLine(line, x, y, fontSize, wordSpacing)
+}
+
+// drawTextLine draws the runs of a laid-out line starting at (x, y), adding
+// wordSpacing points to every space. Text and images are aligned to the bottom
+// of the line, so a tall inline image raises the line instead of overlapping
+// the text beside it.
+func (r *Renderer) drawTextLine(line textLine, x, y, fontSize, wordSpacing float64) {
+	bottom := y + line.height
+	for _, run := range line.runs {
+		start := x
+		link := -1
+		if run.chunk.Link != "" {
+			link = r.beginLink(run.chunk.Link)
+		}
+		if run.chunk.Image != "" {
+			r.drawInlineImage(run.chunk, x, bottom-2-run.chunk.ImageH)
+			x += run.width
+		} else {
+			r.applyFont(run.chunk, fontSize)
+			if wordSpacing == 0 {
+				r.drawText(run.chunk.Text, x, bottom-line.baseHeight)
+				x += run.width
+			} else {
+				spaceWidth, _ := r.pdf.MeasureTextWidth(" ")
+				for i, word := range strings.Split(run.chunk.Text, " ") {
+					if i > 0 {
+						x += spaceWidth + wordSpacing
+					}
+					if word == "" {
+						continue
+					}
+					r.drawText(word, x, bottom-line.baseHeight)
+					width, _ := r.pdf.MeasureTextWidth(word)
+					x += width
+				}
+			}
+		}
+
+		if run.chunk.Link != "" {
+			top, height := bottom-line.baseHeight, line.baseHeight
+			if run.chunk.Image != "" {
+				top, height = bottom-2-run.chunk.ImageH, run.chunk.ImageH
+			}
+			r.addLink(run.chunk.Link, start, top, x-start, height)
+		}
+		r.endLink(link)
+	}
+	r.pdf.SetTextColor(0, 0, 0)
+}
+
+// drawText draws text in the current font with its top-left corner at (x, y).
+func (r *Renderer) drawText(text string, x, y float64) {
+	r.pdf.SetX(x)
+	r.pdf.SetY(y)
+	if err := r.pdf.Cell(nil, text); err != nil {
+		log.Println("Text render failed:", err)
+	}
+}
+
+// drawInlineImage draws an inline image chunk with its top-left corner at (x, y).
+func (r *Renderer) drawInlineImage(chunk TextChunk, x, y float64) {
+	path, cleanup, err := resolveImageSource(chunk.Image)
+	if err != nil {
+		log.Println("Inline image skipped:", err)
+		return
+	}
+	defer cleanup()
+
+	if err := r.pdf.Image(path, x, y, &gopdf.Rect{W: chunk.ImageW, H: chunk.ImageH}); err != nil {
+		log.Println("Image render failed:", err)
+	}
+}
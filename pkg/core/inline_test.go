@@ -0,0 +1,136 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/html"
+)
+
+func TestTextChunk_SameStyle(t *testing.T) {
+	red := &Color{R: 255}
+	otherRed := &Color{R: 255}
+
+	assert.True(t, TextChunk{Bold: true}.sameStyle(TextChunk{Bold: true, Text: "x"}))
+	assert.True(t, TextChunk{Color: red}.sameStyle(TextChunk{Color: otherRed}))
+	assert.False(t, TextChunk{Color: red}.sameStyle(TextChunk{}))
+	assert.False(t, TextChunk{Link: "a"}.sameStyle(TextChunk{Link: "b"}))
+	assert.False(t, TextChunk{Italic: true}.sameStyle(TextChunk{}))
+}
+
+func TestLayoutChunks_BreaksAndImages(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), false)
+
+	chunks := []TextChunk{
+		{Text: "first line"},
+		{LineBreak: true},
+		{Text: "second"},
+		{Image: "data:image/png;base64,xyz", ImageH: 30},
+		{Text: " a long sentence that will certainly need to wrap onto more than one line", Bold: true},
+	}
+	lines := r.layoutChunks(chunks, 120, 12, 16)
+
+	assert.GreaterOrEqual(t, len(lines), 4)
+	assert.Equal(t, "first line", lines[0].runs[0].chunk.Text)
+	assert.Equal(t, "second", lines[1].runs[0].chunk.Text)
+	assert.Equal(t, 16.0, lines[1].runs[1].chunk.ImageW)
+	assert.Equal(t, 32.0, lines[1].height)
+	for _, line := range lines[2:] {
+		assert.LessOrEqual(t, line.width, 120.0)
+		assert.True(t, line.runs[0].chunk.Bold)
+	}
+}
+
+func TestLayoutChunks_MixedStylesShareLine(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), false)
+
+	doc, _ := html.Parse(strings.NewReader(`<p>Hello <strong>world</strong>! This is <em>fine</em>.</p>`))
+	lines := r.layoutChunks(GetStyledTextChunks(findNode(doc, "p")), 495, 12, 16)
+
+	assert.Len(t, lines, 1)
+	var texts []string
+	for _, run := range lines[0].runs {
+		texts = append(texts, run.chunk.Text)
+	}
+	assert.Equal(t, []string{"Hello ", "world", "! This is ", "fine", "."}, texts)
+}
+
+func TestLayoutChunks_BreaksOverlongWord(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), false)
+
+	url := "https://dashboards.example.com/d/abcdef/very-long-dashboard-path?from=now-30d&to=now"
+	lines := r.layoutChunks([]TextChunk{{Text: "See " + url}}, 150, 12, 16)
+
+	assert.Greater(t, len(lines), 2)
+	assert.Equal(t, "See", lines[0].runs[0].chunk.Text)
+	joined := ""
+	for _, line := range lines[1:] {
+		assert.LessOrEqual(t, line.width, 150.0)
+		joined += line.runs[0].chunk.Text
+	}
+	assert.Equal(t, url, joined)
+}
+
+func TestRenderParagraph_BoldWordDoesNotBreakLine(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), false)
+	doc, _ := html.Parse(strings.NewReader(`<p>Only <strong>one</strong> word is <em>styled</em> here.</p>`))
+
+	startY := r.y
+	r.renderParagraph(findNode(doc, "p"))
+
+	lineHeight := r.FontSize.P * 4 / 3
+	assert.Equal(t, startY+lineHeight+4, r.y)
+}
+
+func TestDrawAlignedLine_Alignments(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), false)
+	lines := r.layoutChunks([]TextChunk{{Text: "Lorem ipsum dolor sit amet, consectetur adipiscing elit, " +
+		"sed do eiusmod tempor incididunt ut labore et dolore magna aliqua."}}, 200, 12, 16)
+	assert.Greater(t, len(lines), 1)
+	first, last := lines[0], lines[len(lines)-1]
+
+	r.drawAlignedLine(first, 50, 100, 200, 12, AlignJustify, false)
+	assert.InDelta(t, 250, r.pdf.GetX(), 0.5, "justified line should end at the right edge")
+
+	r.drawAlignedLine(last, 50, 120, 200, 12, AlignJustify, true)
+	assert.InDelta(t, 50+last.width, r.pdf.GetX(), 0.5, "last line should stay left-aligned")
+
+	r.drawAlignedLine(first, 50, 140, 200, 12, AlignRight, false)
+	assert.InDelta(t, 250, r.pdf.GetX(), 0.5)
+
+	r.drawAlignedLine(first, 50, 160, 200, 12, AlignCenter, false)
+	assert.InDelta(t, 50+(200+first.width)/2, r.pdf.GetX(), 0.5)
+}
+
+func TestRenderHTMLLikeToBuffer_AlignedBlocks(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), false)
+
+	buf, err := r.RenderHTMLLikeToBuffer(`
+		<h1 style="text-align: left">Title</h1>
+		<h4>Small heading</h4>
+		<div style="text-align: center"><h2>Centered</h2><p>Centered paragraph</p></div>
+		<p style="text-align: justify">This disclaimer is justified across the full width of the page,
+		with the last line left-aligned.<br>A forced break also ends a line without stretching it.</p>
+		<table><tr><td style="text-align: right">42</td><td>Answer</td></tr></table>`)
+	assert.NoError(t, err)
+	assert.Greater(t, buf.Len(), 100)
+}
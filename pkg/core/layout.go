@@ -2,121 +2,192 @@
 package core
 
 import (
-	"fmt"
-	"golang.org/x/net/html"
 	"log"
-	"os"
-	"strings"
 
 	"github.com/signintech/gopdf"
+	"golang.org/x/net/html"
 )
 
 // walk recursively traverses an HTML node tree and renders
-// supported elements such as h1–h3, p, table, img, and br to the PDF.
+// supported elements such as h1–h6, p, table, lists, img, br, form fields, and
+// signature boxes to the PDF.
+// Any element may force a page break before or after itself, or ask to be
+// kept on one page or with the element that follows it, through the
+// page-break-* styles. Headings are kept with what follows them by default.
 func (r *Renderer) walk(n *html.Node) {
+	if r.measured() {
+		return
+	}
 	if n.Type == html.ElementNode {
+		r.markAnchor(n)
+
+		if forcesPageBreak(n, "before") {
+			r.breakPage()
+		}
+		if forcesPageBreak(n, "after") {
+			defer r.breakPage()
+		}
+		if avoidsPageBreakInside(n) {
+			r.keepTogether(n)
+		}
+		if keepsWithNext(n) {
+			r.keepWithNext(n)
+		}
+		defer r.endTag(r.tagElement(n))
+
 		switch n.Data {
-		case "h1":
-			text := GetTextContent(n)
-			r.checkPageBreak(30)
-			_ = r.pdf.SetFont("Arial", "", r.FontSize.H1)
-			textW, _ := r.pdf.MeasureTextWidth(text)
-			r.pdf.SetX((595.28 - textW) / 2)
-			r.pdf.SetY(r.y)
-			err := r.pdf.Cell(nil, text)
-			if err != nil {
-				return
-			}
-			r.y += 30
-
-		case "h2":
-			text := GetTextContent(n)
-			r.checkPageBreak(30)
-			_ = r.pdf.SetFont("Arial", "", r.FontSize.H2)
-			r.pdf.SetX(50)
-			r.pdf.SetY(r.y)
-			err := r.pdf.Cell(nil, text)
-			if err != nil {
-				return
-			}
-			r.y += 25
-
-		case "h3":
-			text := GetTextContent(n)
-			r.checkPageBreak(14)
-			_ = r.pdf.SetFont("Arial", "", r.FontSize.H3)
-			r.pdf.SetX(50)
-			r.pdf.SetY(r.y)
-			err := r.pdf.Cell(nil, text)
-			if err != nil {
-				return
-			}
-			r.y += 20
+		case "h1", "h2", "h3", "h4", "h5", "h6":
+			r.renderHeading(n)
+			return
 
 		case "p":
+			// Heading, paragraph, and table content (including inline images) is laid
+			// out by their own renderers, so their children are not walked again.
 			r.renderParagraph(n)
+			return
 
 		case "table":
 			r.renderTable(n)
+			return
+
+		case "ul", "ol":
+			r.renderList(n)
+			return
+
+		case "li":
+			r.renderListItem(n)
+			return
+
+		case "a":
+			// A link outside of a paragraph, heading, or table is rendered as its
+			// own line of text so that it is not dropped.
+			r.renderTextBlock(GetStyledTextChunks(n), r.FontSize.P, r.FontSize.P*4/3, textAlign(n))
+			return
+
+		case "div":
+			if isRow(n) {
+				r.renderRow(n)
+				return
+			}
+			if count, gap := r.columnsStyle(n); count > 1 {
+				r.renderColumns(n, count, gap)
+				return
+			}
 
 		case "br":
 			r.y += 10 // handle line breaks with vertical space
 
 		case "img":
 			r.renderImage(n)
+
+		case "input":
+			r.renderFormField(n)
+
+		case "select", "textarea":
+			// Options and the initial text are read by renderFormField.
+			r.renderFormField(n)
+			return
+
+		case "signature":
+			// Like <pagebreak/>, a self-closing <signature/> nests the content
+			// that follows it, so its children are walked after the box.
+			r.renderSignatureBox(n)
+
+		case "pagebreak":
+			// Like <toc/>, <pagebreak/> nests the content that follows it, so the
+			// page is broken and its children are walked as usual.
+			r.breakPage()
+
+		case "toc":
+			// <toc/> is not a void element, so the parser nests the content that
+			// follows it inside; draw the table of contents and keep walking.
+			r.renderTOC()
 		}
 	}
 
+	r.walkChildren(n)
+}
+
+// walkChildren walks the children of n in order.
+func (r *Renderer) walkChildren(n *html.Node) {
 	for c := n.FirstChild; c != nil; c = c.NextSibling {
 		r.walk(c)
 	}
 }
 
-// renderParagraph processes a <p> element and wraps styled text into lines.
-func (r *Renderer) renderParagraph(n *html.Node) {
+// renderHeading renders an <h1>–<h6> element through the inline layout, honoring
+// its text-align. h1–h3 use the configured heading sizes (h1 is centered unless
+// styled otherwise); h4–h6 are drawn in bold at body size.
+func (r *Renderer) renderHeading(n *html.Node) {
+	var size, lineHeight float64
+	bold := false
+	switch n.Data {
+	case "h1":
+		size, lineHeight = r.FontSize.H1, 30
+	case "h2":
+		size, lineHeight = r.FontSize.H2, 25
+	case "h3":
+		size, lineHeight = r.FontSize.H3, 20
+	default:
+		size, lineHeight = r.FontSize.P, r.FontSize.P*4/3+4
+		bold = true
+	}
+	lineHeight = max(lineHeight, size*1.25)
+
 	chunks := GetStyledTextChunks(n)
-	lineHeight := 16.0
-	maxWidth := 495.0
-
-	currentLine := ""
-	currentItalic := true
-	currentBold := false
-
-	flushLine := func() {
-		if currentLine != "" {
-			r.checkPageBreak(lineHeight)
-			r.pdf.SetX(50)
-			r.pdf.SetY(r.y)
-			SetFont(r.pdf, currentItalic, currentBold)
-			err := r.pdf.Cell(nil, currentLine)
-			if err != nil {
-				return
-			}
-			r.y += lineHeight
+	if bold {
+		for i := range chunks {
+			chunks[i].Bold = true
 		}
 	}
 
-	for _, chunk := range chunks {
-		words := strings.Fields(chunk.Text)
-		for _, word := range words {
-			testLine := strings.TrimSpace(currentLine + " " + word)
-			width, _ := r.pdf.MeasureTextWidth(testLine)
-
-			if width > maxWidth || chunk.Italic != currentItalic || chunk.Bold != currentBold {
-				flushLine()
-				currentLine = word
-				currentItalic = chunk.Italic
-				currentBold = chunk.Bold
-			} else {
-				if currentLine != "" {
-					currentLine += " "
-				}
-				currentLine += word
-			}
+	// Break the page up front so the bookmark points at the heading's final position.
+	r.checkPageBreak(lineHeight)
+	r.addBookmark(n)
+	r.renderTextBlock(chunks, size, lineHeight, textAlign(n))
+}
+
+// renderParagraph processes a <p> element and wraps styled text into lines.
+// Bold, italic, colored, and linked runs are placed side by side on the same
+// line; a line is only wrapped when the next word no longer fits. Page breaks
+// inside the paragraph honor the minimum orphan and widow lines.
+func (r *Renderer) renderParagraph(n *html.Node) {
+	chunks := GetStyledTextChunks(n)
+	lineHeight := r.FontSize.P * 4 / 3
+
+	lines := r.layoutChunks(chunks, r.width, r.FontSize.P, lineHeight)
+	orphans, widows := r.orphansAndWidows(n)
+	r.drawLines(lines, r.FontSize.P, textAlign(n), orphans, widows)
+	r.y += 4
+}
+
+// renderTextBlock lays out styled chunks across the content width and draws
+// them line by line with the given alignment, breaking pages as needed.
+func (r *Renderer) renderTextBlock(chunks []TextChunk, fontSize, lineHeight float64, align Alignment) {
+	lines := r.layoutChunks(chunks, r.width, fontSize, lineHeight)
+	r.drawLines(lines, fontSize, align, 1, 1)
+}
+
+// drawLines draws laid-out lines across the content width, breaking pages as
+// needed. At every page break, at least orphans lines are left at the bottom
+// of the page and at least widows lines are carried over; if that is not
+// possible the remaining lines start on the next page. Lines that do not fit
+// on an empty page even so fill it.
+func (r *Renderer) drawLines(lines []textLine, fontSize float64, align Alignment, orphans, widows int) {
+	for i := 0; i < len(lines) && !r.measured(); {
+		split := i + r.linesBeforeBreak(lines[i:], orphans, widows)
+		if split == i && r.y <= r.top() {
+			split = i + max(r.linesBeforeBreak(lines[i:], 1, 1), 1)
+		}
+		for ; i < split && !r.measured(); i++ {
+			r.checkPageBreak(lines[i].height)
+			r.drawAlignedLine(lines[i], r.left, r.y, r.width, fontSize, align, i == len(lines)-1)
+			r.y += lines[i].height
+		}
+		if i < len(lines) {
+			r.breakPage()
 		}
 	}
-	flushLine()
-	r.y += 4
 }
 
 // renderImage processes an <img> element, extracting alignment and base64/file path.
@@ -128,46 +199,27 @@ func (r *Renderer) renderImage(n *html.Node) {
 			break
 		}
 	}
-	align := "left"
-	if n.Parent != nil {
-		for _, attr := range n.Parent.Attr {
-			if attr.Key == "style" {
-				if strings.Contains(attr.Val, "text-align: center") {
-					align = "center"
-				} else if strings.Contains(attr.Val, "text-align: right") {
-					align = "right"
-				}
-			}
-		}
-	}
+	align := textAlign(n)
 
-	if strings.HasPrefix(src, "file://") {
-		path := strings.TrimPrefix(src, "file://")
-		r.drawAlignedImage(path, align)
-	} else if strings.HasPrefix(src, "data:image/") {
-		if path, err := saveBase64ImageToTempFile(src); err == nil {
-			r.drawAlignedImage(path, align)
-			err := os.Remove(path)
-			if err != nil {
-				return
-			}
-		}
+	path, cleanup, err := resolveImageSource(src)
+	if err != nil {
+		return
 	}
+	defer cleanup()
+	r.drawAlignedImage(path, align)
 }
 
 // drawAlignedImage renders an image with specified horizontal alignment.
-func (r *Renderer) drawAlignedImage(path, align string) {
-	imgW := 100.0
+func (r *Renderer) drawAlignedImage(path string, align Alignment) {
+	imgW := min(100.0, r.width)
 	imgH := 60.0
 
-	var x float64
+	x := r.left
 	switch align {
-	case "center":
-		x = (595.28 - imgW) / 2
-	case "right":
-		x = 595.28 - imgW - 50
-	default:
-		x = 50
+	case AlignCenter:
+		x += (r.width - imgW) / 2
+	case AlignRight:
+		x += r.width - imgW
 	}
 
 	if err := r.pdf.Image(path, x, r.y, &gopdf.Rect{W: imgW, H: imgH}); err != nil {
@@ -179,28 +231,17 @@ func (r *Renderer) drawAlignedImage(path, align string) {
 	r.checkPageBreak(30)
 }
 
-// flushPage finishes the current page, adds footer/timestamp, and starts a new page.
+// flushPage starts a new page and runs the page decorators' OnPageStart hooks
+// on it. Footers, page numbers, and timestamps are drawn on every page by the
+// OnPageEnd hooks once layout is complete.
 func (r *Renderer) flushPage() {
-	r.drawFooterAtFixedPosition()
 	r.pdf.AddPage()
 	r.pageNumber++
-	r.y = pageMargin
-
-	if r.backgroundImg != "" {
-		_ = r.pdf.Image(r.backgroundImg, 0, 0, &gopdf.Rect{W: 595.28, H: 841.89})
-	}
-	if r.headerImg != "" {
-		_ = r.pdf.Image(r.headerImg, 50, 20, &gopdf.Rect{W: 495.0, H: 40.0})
-		r.y += 50
-	}
-	if r.footerImg != "" {
-		_ = r.pdf.Image(r.footerImg, 50, 800, &gopdf.Rect{W: 495.0, H: 30.0})
-	}
-
-	r.drawTimestamp()
+	r.startPage()
 }
 
-// drawFooterAtFixedPosition draws static footer and page number at the bottom of each page.
+// drawFooterAtFixedPosition draws the static footer text at the bottom of each page.
+// Page numbers are drawn separately by drawPageNumber once the total is known.
 func (r *Renderer) drawFooterAtFixedPosition() {
 	if r.footerText != "" {
 		_ = r.pdf.SetFont("Arial", "", r.FontSize.Footer)
@@ -211,105 +252,30 @@ func (r *Renderer) drawFooterAtFixedPosition() {
 			return
 		}
 	}
-	if r.showPageNumber {
-		_ = r.pdf.SetFont("Arial", "", r.FontSize.Footer)
-		r.pdf.SetY(820)
-		r.pdf.SetX(500)
-		err := r.pdf.Cell(nil, fmt.Sprintf("Page %d", r.pageNumber))
-		if err != nil {
-			return
-		}
-	}
 }
 
 // checkPageBreak checks if the current y-position plus upcoming block height
-// will overflow the page, and triggers a flushPage if so.
+// will overflow the page, and triggers a flushPage if so. Any queued anchors
+// are then placed at the position where the upcoming block starts.
 func (r *Renderer) checkPageBreak(nextBlockHeight float64) {
-	if r.y+nextBlockHeight > contentLimit {
-		log.Println("Page break triggered")
-		r.flushPage()
-	}
-}
-
-// renderTable walks through the table node and renders its rows.
-func (r *Renderer) renderTable(n *html.Node) {
-	_ = r.pdf.SetFont("Arial", "", r.FontSize.P)
-	r.walkTableRows(n)
-	r.y += 10
-}
-
-// walkTableRows traverses table rows and renders each.
-func (r *Renderer) walkTableRows(n *html.Node) {
-	for c := n.FirstChild; c != nil; c = c.NextSibling {
-		if c.Type == html.ElementNode {
-			if c.Data == "tr" {
-				r.checkPageBreak(30)
-				r.renderTableRow(c)
-			} else {
-				r.walkTableRows(c)
-			}
+	switch {
+	case r.inRow:
+		// Rows are kept together by renderRow; their columns never break.
+	case r.columns != nil:
+		// Inside a multi-column section the block moves to the next column,
+		// unless the current one is still empty.
+		if r.y+nextBlockHeight > r.columns.bottom() && r.y > r.columns.top {
+			r.nextColumn()
 		}
-	}
-}
-
-// renderTableRow renders a single table row with dynamic height and column width.
-func (r *Renderer) renderTableRow(tr *html.Node) {
-	lineHeight := 14.0
-	numCols := r.countColumns(tr)
-	if numCols == 0 {
+	case r.outOfFlow():
+		// Running headers and footers are drawn into the page margins, and
+		// measured content is laid out as one block, so neither breaks pages.
 		return
+	case r.y+nextBlockHeight > contentLimit:
+		log.Println("Page break triggered")
+		r.flushPage()
 	}
-	colWidth := (595.28 - 100) / float64(numCols)
-
-	maxLines := 1
-	cellTexts := []string{}
-	for td := tr.FirstChild; td != nil; td = td.NextSibling {
-		if td.Type == html.ElementNode && (td.Data == "td" || td.Data == "th") {
-			text := GetTextContent(td)
-			cellTexts = append(cellTexts, text)
-			lines := wrapText(r.pdf, text, colWidth-8)
-			if len(lines) > maxLines {
-				maxLines = len(lines)
-			}
-		}
-	}
-	rowHeight := float64(maxLines) * lineHeight
-	r.checkPageBreak(rowHeight)
-
-	isHeader := tr.FirstChild != nil && tr.FirstChild.Data == "th"
-	if isHeader {
-		_ = r.pdf.SetFont("Arial", "B", r.FontSize.P)
-	} else {
-		_ = r.pdf.SetFont("Arial", "", r.FontSize.P)
-	}
-
-	x := 50.0
-	startY := r.y
-	for _, text := range cellTexts {
-		r.pdf.RectFromUpperLeftWithStyle(x, startY, colWidth, rowHeight, "D")
-		lines := wrapText(r.pdf, text, colWidth-8)
-		for j, line := range lines {
-			r.pdf.SetX(x + 4)
-			r.pdf.SetY(startY + float64(j)*lineHeight + 2)
-			err := r.pdf.Cell(nil, line)
-			if err != nil {
-				return
-			}
-		}
-		x += colWidth
-	}
-	r.y += rowHeight
-}
-
-// countColumns counts how many <td> or <th> elements are in a given <tr>.
-func (r *Renderer) countColumns(tr *html.Node) int {
-	count := 0
-	for td := tr.FirstChild; td != nil; td = td.NextSibling {
-		if td.Type == html.ElementNode && (td.Data == "td" || td.Data == "th") {
-			count++
-		}
-	}
-	return count
+	r.resolveAnchors()
 }
 
 // drawTimestamp renders a timestamp string in the top-right of the page if set.
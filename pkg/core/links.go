@@ -0,0 +1,80 @@
+// File: renderer/links.go
+package core
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// DefaultLinkStyle returns the link style used when none is configured:
+// blue, underlined text.
+func DefaultLinkStyle() LinkStyle {
+	return LinkStyle{
+		Color:     Color{R: 0, G: 0, B: 238},
+		Underline: true,
+	}
+}
+
+// collectAnchorIDs records the id attribute of every element in the document,
+// so that internal "#id" links are only emitted for targets that exist.
+func (r *Renderer) collectAnchorIDs(n *html.Node) {
+	if n.Type == html.ElementNode {
+		if id := getAttr(n, "id"); id != "" {
+			if r.anchorIDs == nil {
+				r.anchorIDs = map[string]bool{}
+			}
+			r.anchorIDs[id] = true
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		r.collectAnchorIDs(c)
+	}
+}
+
+// markAnchor queues an element id as a link destination. The anchor is placed
+// by the next checkPageBreak, once the position of the element's first content
+// (after any page break) is known.
+func (r *Renderer) markAnchor(n *html.Node) {
+	if r.outOfFlow() {
+		return
+	}
+	if id := getAttr(n, "id"); id != "" {
+		r.pendingAnchors = append(r.pendingAnchors, id)
+	}
+}
+
+// resolveAnchors places all queued anchors at the current position and
+// records the page each one landed on. Anchors stay queued while content is
+// laid out outside the page flow, e.g. while it is measured.
+func (r *Renderer) resolveAnchors() {
+	if len(r.pendingAnchors) == 0 || r.outOfFlow() {
+		return
+	}
+	if r.anchorPages == nil {
+		r.anchorPages = map[string]int{}
+	}
+	r.pdf.SetY(r.y)
+	for _, id := range r.pendingAnchors {
+		r.pdf.SetAnchor(id)
+		r.anchorPages[id] = r.pageNumber
+	}
+	r.pendingAnchors = nil
+}
+
+// addLink adds a clickable link annotation over the given area. Hrefs starting
+// with "#" link to the element with that id; anything else is an external URI.
+// Links outside the page flow are skipped: running headers and footers are
+// drawn after layout, when gopdf would add the annotation to the last page.
+func (r *Renderer) addLink(href string, x, y, w, h float64) {
+	if r.outOfFlow() {
+		return
+	}
+	if anchor, ok := strings.CutPrefix(href, "#"); ok {
+		if r.anchorIDs[anchor] {
+			r.pdf.AddInternalLink(anchor, x, y, w, h)
+		}
+		return
+	}
+	r.pdf.AddExternalLink(href, x, y, w, h)
+}
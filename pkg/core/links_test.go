@@ -0,0 +1,62 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/html"
+)
+
+func TestCollectAnchorIDs(t *testing.T) {
+	doc, _ := html.Parse(strings.NewReader(`<h2 id="appendix">A</h2><div><p id="note">n</p></div><p>x</p>`))
+	r := &Renderer{}
+	r.collectAnchorIDs(doc)
+
+	assert.Equal(t, map[string]bool{"appendix": true, "note": true}, r.anchorIDs)
+}
+
+func TestGetStyledTextChunks_LinkIgnoresInheritedColor(t *testing.T) {
+	doc, _ := html.Parse(strings.NewReader(
+		`<p style="color: red">See <a href="https://example.com">docs</a> or <a href="#x" style="color: green">x</a></p>`))
+	chunks := GetStyledTextChunks(findNode(doc, "p"))
+
+	assert.Len(t, chunks, 4)
+	assert.Equal(t, &Color{R: 255}, chunks[0].Color)
+	assert.Equal(t, "https://example.com", chunks[1].Link)
+	assert.Nil(t, chunks[1].Color)
+	assert.Equal(t, "#x", chunks[3].Link)
+	assert.Equal(t, &Color{G: 128}, chunks[3].Color)
+}
+
+func TestRenderHTMLLikeToBuffer_LinksAndAnchors(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), false)
+	r.pdf.SetNoCompression()
+
+	buf, err := r.RenderHTMLLikeToBuffer(`
+		<p>Open the <a href="https://dashboards.example.com/d/1">dashboard</a> or jump to the <a href="#appendix">appendix</a>.</p>
+		<div><a href="https://example.com/standalone">Standalone link</a></div>
+		<p><a href="#missing">Dangling link</a></p>
+		<h2 id="appendix">Appendix</h2>
+		<p>Details.</p>`)
+	assert.NoError(t, err)
+
+	out := buf.String()
+	assert.Contains(t, out, "/URI (https://dashboards.example.com/d/1)")
+	assert.Contains(t, out, "/URI (https://example.com/standalone)")
+	assert.Equal(t, 1, strings.Count(out, "/Dest ["), "only the link with an existing target becomes internal")
+	assert.Contains(t, out, "0.000 0.000 0.933 rg", "links use the default link color")
+}
+
+func TestRendererFactory_WithLinkStyle(t *testing.T) {
+	style := LinkStyle{Color: Color{R: 10, G: 20, B: 30}}
+	factory := NewRendererFactory().WithLinkStyle(style)
+	assert.Equal(t, style, factory.LinkStyle)
+
+	renderer, err := factory.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, style, renderer.LinkStyle)
+}
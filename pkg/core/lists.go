@@ -0,0 +1,103 @@
+// File: renderer/lists.go
+package core
+
+import (
+	"strconv"
+
+	"golang.org/x/net/html"
+)
+
+// listIndent is how far list items are indented from the surrounding content.
+// Their bullets and numbers are drawn in the indentation.
+const listIndent = 20.0
+
+// listLevel is a <ul> or <ol> being laid out.
+type listLevel struct {
+	ordered bool // Whether items are numbered rather than bulleted.
+	next    int  // Number of the next item of an ordered list.
+}
+
+// listItemBlocks are the elements inside an <li> that are laid out as blocks
+// of their own rather than as part of the item's text.
+var listItemBlocks = map[string]bool{
+	"p": true, "div": true, "ul": true, "ol": true, "table": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"blockquote": true, "section": true, "article": true, "pagebreak": true,
+	"toc": true, "signature": true, "input": true, "select": true, "textarea": true,
+}
+
+// renderList lays out the items of a <ul> or <ol>, indented by listIndent.
+// Ordered lists are numbered from their start attribute, or from 1.
+func (r *Renderer) renderList(n *html.Node) {
+	level := listLevel{ordered: n.Data == "ol", next: 1}
+	if v, err := strconv.Atoi(getAttr(n, "start")); err == nil {
+		level.next = v
+	}
+	r.lists = append(r.lists, level)
+	r.left += listIndent
+	r.width -= listIndent
+	defer func() {
+		r.left -= listIndent
+		r.width += listIndent
+		r.lists = r.lists[:len(r.lists)-1]
+	}()
+	r.walkChildren(n)
+}
+
+// renderListItem lays out an <li>: its label, a bullet or the item number,
+// right-aligned in the list's indentation, and its content next to it. The
+// item's text is laid out like a paragraph; block elements inside it, such as
+// nested lists, follow in order. In tagged PDFs the label and the content
+// become the item's Lbl and LBody.
+func (r *Renderer) renderListItem(n *html.Node) {
+	label := "•"
+	if len(r.lists) > 0 {
+		list := &r.lists[len(r.lists)-1]
+		if list.ordered {
+			label = strconv.Itoa(list.next) + "."
+		}
+		list.next++
+	}
+
+	fontSize, lineHeight := r.FontSize.P, r.FontSize.P*4/3
+	r.checkPageBreak(lineHeight)
+	if lines := r.layoutChunks([]TextChunk{{Text: label}}, listIndent*4, fontSize, lineHeight); len(lines) > 0 {
+		elem := r.beginTag("Lbl", "")
+		r.drawTextLine(lines[0], r.left-lines[0].width-6, r.y, fontSize, 0)
+		r.endTag(elem)
+	}
+
+	body := r.beginTag("LBody", "")
+	defer r.endTag(body)
+
+	// Runs of text and inline elements between blocks are laid out as
+	// paragraphs, skipping the item's children outside the run.
+	start, y := n.FirstChild, r.y
+	flush := func(end *html.Node) {
+		inRun := map[*html.Node]bool{}
+		for c := start; c != end; c = c.NextSibling {
+			inRun[c] = true
+		}
+		chunks := styledTextChunks(n, func(c *html.Node) bool {
+			return c.Parent == n && !inRun[c]
+		})
+		if len(chunks) > 0 {
+			lines := r.layoutChunks(chunks, r.width, fontSize, lineHeight)
+			orphans, widows := r.orphansAndWidows(n)
+			r.drawLines(lines, fontSize, textAlign(n), orphans, widows)
+			r.y += 4
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && listItemBlocks[c.Data] {
+			flush(c)
+			r.walk(c)
+			start = c.NextSibling
+		}
+	}
+	flush(nil)
+	if r.y == y {
+		// An empty item still takes up the line of its label.
+		r.y += lineHeight
+	}
+}
@@ -0,0 +1,46 @@
+package core
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderHTMLLikeToBuffer_Lists(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+
+	buf, err := r.RenderHTMLLikeToBuffer(`
+		<ol start="3"><li>Third</li><li>Fourth <strong>item</strong><ul><li>Nested</li></ul>and more</li><li></li></ol>
+		<p>After</p>`)
+	require.NoError(t, err)
+
+	text := pageText(t, buf.Bytes())
+	require.Len(t, text, 1)
+	assert.Contains(t, text[0], "3.Third4.Fourth item•Nestedand more5.After")
+
+	// Item text is indented by one level per list; labels sit in the indentation.
+	positions := regexp.MustCompile(`(?m)^([\d.]+) [\d.]+ TD$`).FindAllStringSubmatch(pageContents(t, buf.Bytes())[0], -1)
+	var xs []string
+	for _, m := range positions {
+		xs = append(xs, m[1])
+	}
+	assert.Equal(t, []string{"54.00", "70.00", "54.00", "70.00", "107.98", "79.80", "90.00", "70.00", "54.00", "50.00"}, xs[:10])
+	assert.Equal(t, 50.0, r.left, "the indentation ends with the list")
+	assert.Equal(t, 495.0, r.width)
+}
+
+func TestRenderListItem_WithoutList(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+
+	buf, err := r.RenderHTMLLikeToBuffer(`<li>Stray item</li>`)
+	require.NoError(t, err)
+	assert.Contains(t, pageText(t, buf.Bytes())[0], "•Stray item")
+}
@@ -0,0 +1,58 @@
+// File: renderer/merge.go
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/signintech/gopdf"
+)
+
+// Merge concatenates PDF documents, such as a cover page, several rendered
+// reports, and an appendix, into one document whose pages keep their size.
+//
+// Each page is imported as a drawing, so what is not part of the page content
+// (links, bookmarks, form fields, signatures, the structure tree of tagged
+// PDFs, and document properties) is not carried over. Page numbers are drawn
+// by the renderer, so for numbering across the merged document, render each
+// report with PageNumberFormat.StartAt set to its first page in the merged
+// document and PageNumberFormat.Total to the combined page count.
+// Password-protected documents cannot be merged.
+func Merge(outputs ...[]byte) ([]byte, error) {
+	if len(outputs) == 0 {
+		return nil, errors.New("no documents to merge")
+	}
+	pdf := &gopdf.GoPdf{}
+	pdf.Start(gopdf.Config{PageSize: *gopdf.PageSizeA4})
+
+	for i, output := range outputs {
+		if bytes.Contains(output, []byte("/Encrypt")) {
+			return nil, fmt.Errorf("document %d is password-protected", i+1)
+		}
+		source := io.ReadSeeker(bytes.NewReader(output))
+		err := recoverImport(func() {
+			sizes := pdf.GetStreamPageSizes(&source)
+			for page := 1; page <= len(sizes); page++ {
+				box := sizes[page][pdfPageBox]
+				size := &gopdf.Rect{W: box["w"], H: box["h"]}
+				pdf.AddPageWithOption(gopdf.PageOption{PageSize: size})
+				tpl := pdf.ImportPageStream(&source, page, pdfPageBox)
+				pdf.UseImportedTemplate(tpl, 0, 0, size.W, size.H)
+			}
+		})
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %w", i+1, err)
+		}
+	}
+	if pdf.GetNumberOfPages() == 0 {
+		return nil, errors.New("no pages to merge")
+	}
+
+	var b bytes.Buffer
+	if _, err := pdf.WriteTo(&b); err != nil {
+		return nil, fmt.Errorf("failed to write merged PDF: %w", err)
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// renderPages renders body and fails the test on error.
+func renderPages(t *testing.T, r *Renderer, body string) []byte {
+	buf, err := r.RenderHTMLLikeToBuffer(body)
+	require.NoError(t, err)
+	return buf.Bytes()
+}
+
+// pageCount returns the number of pages of pdf.
+func pageCount(t *testing.T, pdf []byte) int {
+	return len(pageContents(t, pdf))
+}
+
+func TestMerge(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	report := renderPages(t, r, "<h1>Report</h1>"+strings.Repeat("<p>Line of text</p>", 80))
+	reportPages := pageCount(t, report)
+	require.Greater(t, reportPages, 1)
+
+	// Documents finished with an incremental update are merged as well.
+	r, _ = NewRenderer(defaultFontSizes(), true)
+	r.PDFA = true
+	appendix := renderPages(t, r, "<h1>Appendix</h1>")
+
+	merged, err := Merge(report, appendix)
+	require.NoError(t, err)
+	contents := pageContents(t, merged)
+	assert.Len(t, contents, reportPages+1)
+	for i, content := range contents {
+		assert.Regexp(t, `/GOFPDITPL\d+ Do`, content, "page %d draws its imported page", i+1)
+	}
+}
+
+func TestMerge_CombinedPageNumbers(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	bodies := []string{strings.Repeat("<p>Line of text</p>", 80), "<p>Appendix</p>"}
+
+	// A first pass counts the pages of every report.
+	var counts []int
+	total := 0
+	for _, body := range bodies {
+		r, _ := NewRenderer(defaultFontSizes(), true)
+		counts = append(counts, pageCount(t, renderPages(t, r, body)))
+		total += counts[len(counts)-1]
+	}
+
+	var outputs [][]byte
+	start := 1
+	for i, body := range bodies {
+		r, _ := NewRenderer(defaultFontSizes(), true)
+		r.PageNumbers = PageNumberFormat{Template: "Page {page} of {total}", StartAt: start, Total: total}
+		output := renderPages(t, r, body)
+		pages := pageText(t, output)
+		require.Len(t, pages, counts[i])
+		assert.Contains(t, pages[0], fmt.Sprintf("Page %d of %d", start, total))
+		assert.Contains(t, pages[len(pages)-1], fmt.Sprintf("Page %d of %d", start+counts[i]-1, total))
+		outputs = append(outputs, output)
+		start += counts[i]
+	}
+
+	merged, err := Merge(outputs...)
+	require.NoError(t, err)
+	assert.Equal(t, total, pageCount(t, merged))
+}
+
+func TestMerge_Errors(t *testing.T) {
+	_, err := Merge()
+	assert.ErrorContains(t, err, "no documents")
+
+	_, err = Merge([]byte("not a PDF"))
+	assert.ErrorContains(t, err, "document 1: cannot import PDF")
+
+	_, err = Merge([]byte("%PDF-1.4\n1 0 obj\n<< /Encrypt 2 0 R >>\nendobj\n"))
+	assert.ErrorContains(t, err, "password-protected")
+}
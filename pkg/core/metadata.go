@@ -0,0 +1,72 @@
+// File: renderer/metadata.go
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+	"unicode/utf16"
+
+	"github.com/signintech/gopdf"
+)
+
+// applyMetadata writes the document properties to the PDF's information
+// dictionary. The title defaults to the template's <title> and the creation
+// date to the current time. It returns the properties that were written.
+//
+// The creation date is written in UTC: gopdf drops the minutes of the time
+// zone offset, which would put it at odds with the XMP metadata of PDF/A
+// documents in zones such as +05:30.
+func (r *Renderer) applyMetadata() Metadata {
+	m := r.Metadata
+	if m.Title == "" {
+		m.Title = r.title
+	}
+	if m.CreationDate.IsZero() {
+		m.CreationDate = time.Now()
+	}
+	m.CreationDate = m.CreationDate.UTC()
+	r.pdf.SetInfo(gopdf.PdfInfo{
+		Title:        m.Title,
+		Author:       m.Author,
+		Subject:      m.Subject,
+		Creator:      m.Creator,
+		Producer:     m.Producer,
+		CreationDate: m.CreationDate,
+	})
+	return m
+}
+
+// addKeywords adds a /Keywords entry to the information dictionary of a PDF
+// written by gopdf, which has no field for it. gopdf writes the dictionary
+// inline in the trailer, after the cross-reference table, so inserting into it
+// does not move any object.
+func addKeywords(pdf []byte, keywords string) []byte {
+	if keywords == "" {
+		return pdf
+	}
+	marker := []byte("/Info <<\n")
+	i := bytes.LastIndex(pdf, marker)
+	if i < 0 {
+		return pdf
+	}
+	i += len(marker)
+	entry := fmt.Sprintf("/Keywords %s\n", pdfTextString(keywords))
+
+	out := make([]byte, 0, len(pdf)+len(entry))
+	out = append(out, pdf[:i]...)
+	out = append(out, entry...)
+	return append(out, pdf[i:]...)
+}
+
+// pdfTextString encodes s as a hexadecimal UTF-16BE PDF text string with a
+// byte order mark.
+func pdfTextString(s string) string {
+	var b bytes.Buffer
+	b.WriteString("<FEFF")
+	for _, u := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", u)
+	}
+	b.WriteString(">")
+	return b.String()
+}
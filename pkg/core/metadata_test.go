@@ -0,0 +1,51 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderHTMLLikeToBuffer_Metadata(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	r.Metadata = Metadata{
+		Author:       "Finance",
+		Keywords:     "sales, q3",
+		CreationDate: time.Date(2025, 7, 1, 9, 30, 0, 0, time.UTC),
+	}
+
+	buf, err := r.RenderHTMLLikeToBuffer(`<html><head><title>Q3</title></head><body><p>Body</p></body></html>`)
+	assert.NoError(t, err)
+
+	pdf := buf.String()
+	assert.Contains(t, pdf, "/Title "+pdfTextString("Q3"), "the title defaults to <title>")
+	assert.Contains(t, pdf, "/Author "+pdfTextString("Finance"))
+	assert.Contains(t, pdf, "/Keywords "+pdfTextString("sales, q3"))
+	assert.Contains(t, pdf, "/CreationDate(D:20250701093000+00'00')")
+}
+
+func TestRenderHTMLLikeToBuffer_MetadataDefaults(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	r.Metadata.Title = "Explicit"
+
+	buf, err := r.RenderHTMLLikeToBuffer(`<html><head><title>Ignored</title></head><body><p>Body</p></body></html>`)
+	assert.NoError(t, err)
+
+	pdf := buf.String()
+	assert.Contains(t, pdf, "/Title "+pdfTextString("Explicit"))
+	assert.Contains(t, pdf, "/CreationDate(D:"+time.Now().Format("2006"))
+	assert.NotContains(t, pdf, "/Keywords")
+}
+
+func TestPDFTextString(t *testing.T) {
+	assert.Equal(t, "<FEFF0041>", pdfTextString("A"))
+	assert.Equal(t, "<FEFF00FC>", pdfTextString("ü"))
+	assert.Equal(t, "<FEFFD83DDCC8>", pdfTextString("📈"), "characters outside the BMP use surrogate pairs")
+}
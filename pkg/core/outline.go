@@ -0,0 +1,159 @@
+// File: renderer/outline.go
+package core
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/signintech/gopdf"
+	"golang.org/x/net/html"
+)
+
+// outlineLevel is an open bookmark on the nesting stack, together with the
+// heading level it was created for.
+type outlineLevel struct {
+	level int
+	node  *gopdf.OutlineNode
+}
+
+// addBookmark adds a PDF outline entry for an h1–h3 heading at the current
+// position, nested below the closest preceding heading of a higher level.
+// Headings with data-bookmark="false" are left out.
+func (r *Renderer) addBookmark(n *html.Node) {
+	if !r.Bookmarks || r.outOfFlow() || getAttr(n, "data-bookmark") == "false" {
+		return
+	}
+	level := 0
+	switch n.Data {
+	case "h1":
+		level = 1
+	case "h2":
+		level = 2
+	case "h3":
+		level = 3
+	default:
+		return
+	}
+	title := GetTextContent(n)
+	if title == "" {
+		return
+	}
+
+	r.pdf.SetY(r.y)
+	node := &gopdf.OutlineNode{Obj: r.pdf.AddOutlineWithPosition(title)}
+
+	for len(r.outlineStack) > 0 && r.outlineStack[len(r.outlineStack)-1].level >= level {
+		r.outlineStack = r.outlineStack[:len(r.outlineStack)-1]
+	}
+	if len(r.outlineStack) == 0 {
+		r.outlineRoots = append(r.outlineRoots, node)
+	} else {
+		parent := r.outlineStack[len(r.outlineStack)-1].node
+		parent.Children = append(parent.Children, node)
+	}
+	r.outlineStack = append(r.outlineStack, outlineLevel{level: level, node: node})
+}
+
+// linkOutlines turns the flat list of outline entries created by gopdf into a
+// tree by rewriting their parent/sibling/child references. It must run once,
+// after layout and before the PDF is written.
+func (r *Renderer) linkOutlines() {
+	linkOutlineNodes(r.outlineRoots)
+}
+
+// linkOutlineNodes links a list of sibling outline nodes to each other and to
+// their children. Top-level nodes keep the outline root that gopdf assigned as
+// their parent.
+func linkOutlineNodes(nodes []*gopdf.OutlineNode) {
+	for i, node := range nodes {
+		prev, next := -1, -1
+		if i > 0 {
+			prev = nodes[i-1].Obj.GetIndex()
+		}
+		if i < len(nodes)-1 {
+			next = nodes[i+1].Obj.GetIndex()
+		}
+		node.Obj.SetPrev(prev)
+		node.Obj.SetNext(next)
+
+		if len(node.Children) == 0 {
+			continue
+		}
+		node.Obj.SetFirst(node.Children[0].Obj.GetIndex())
+		node.Obj.SetLast(node.Children[len(node.Children)-1].Obj.GetIndex())
+		for _, child := range node.Children {
+			child.Obj.SetParent(node.Obj.GetIndex())
+		}
+		linkOutlineNodes(node.Children)
+	}
+}
+
+// countOutlines completes the bookmark tree of a rendered PDF. gopdf points the
+// outline root's /Last at the last bookmark added, which may be a nested one,
+// gives top-level bookmarks the wrong parent, and writes no /Count for
+// bookmarks with children. The root is pointed at the first and last top-level
+// bookmarks, which are made its children, and every bookmark with children is
+// given the number of its descendants, which shows it expanded.
+func countOutlines(pdf []byte, roots []*gopdf.OutlineNode) ([]byte, error) {
+	u, err := newPDFUpdate(pdf)
+	if err != nil {
+		return nil, err
+	}
+	rootID, err := u.root()
+	if err != nil {
+		return nil, err
+	}
+	catalog, err := u.dict(rootID)
+	if err != nil {
+		return nil, err
+	}
+	outlinesID, ok := refID(dictGet(catalog, "Outlines"))
+	if !ok {
+		return nil, errors.New("PDF catalog has no /Outlines")
+	}
+	outlines, err := u.dict(outlinesID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, node := range roots {
+		id := node.Obj.GetIndex()
+		item, err := u.dict(id)
+		if err != nil {
+			return nil, err
+		}
+		u.set(id, formatDict(dictSet(item, "Parent", ref(outlinesID))))
+	}
+	total, err := countOutlineNodes(u, roots)
+	if err != nil {
+		return nil, err
+	}
+	outlines = dictSet(outlines, "First", ref(roots[0].Obj.GetIndex()))
+	outlines = dictSet(outlines, "Last", ref(roots[len(roots)-1].Obj.GetIndex()))
+	outlines = dictSet(outlines, "Count", strconv.Itoa(total))
+	u.set(outlinesID, formatDict(outlines))
+	return u.rewrite()
+}
+
+// countOutlineNodes sets /Count on the outline items of nodes that have
+// children and returns the number of items in nodes and below them.
+func countOutlineNodes(u *pdfUpdate, nodes []*gopdf.OutlineNode) (int, error) {
+	total := len(nodes)
+	for _, node := range nodes {
+		if len(node.Children) == 0 {
+			continue
+		}
+		count, err := countOutlineNodes(u, node.Children)
+		if err != nil {
+			return 0, err
+		}
+		id := node.Obj.GetIndex()
+		item, err := u.dict(id)
+		if err != nil {
+			return 0, err
+		}
+		u.set(id, formatDict(dictSet(item, "Count", strconv.Itoa(count))))
+		total += count
+	}
+	return total, nil
+}
@@ -0,0 +1,93 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderHTMLLikeToBuffer_Bookmarks(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	r.Bookmarks = true
+	r.pdf.SetNoCompression()
+
+	buf, err := r.RenderHTMLLikeToBuffer(`
+		<h1>Compliance Report</h1>
+		<h2>Access Control</h2>
+		<h3>Findings</h3>
+		<h3 data-bookmark="false">Internal notes</h3>
+		<h2>Encryption</h2>
+		<h1>Appendix</h1>
+		<h4>Not bookmarked</h4>`)
+	assert.NoError(t, err)
+
+	assert.Len(t, r.outlineRoots, 2)
+	report, appendix := r.outlineRoots[0], r.outlineRoots[1]
+	assert.Len(t, report.Children, 2)
+	assert.Len(t, report.Children[0].Children, 1)
+	assert.Empty(t, appendix.Children)
+
+	out := buf.String()
+	assert.Contains(t, out, "/Count 5")
+	assert.Contains(t, out, "/Type /Outlines")
+	assert.Equal(t, 5, strings.Count(out, "/Title <FEFF"))
+}
+
+func TestRenderHTMLLikeToBuffer_BookmarksEndingNested(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	r.Bookmarks = true
+
+	buf, err := r.RenderHTMLLikeToBuffer(`<h1>A</h1><h2>B</h2><h3>C</h3>`)
+	require.NoError(t, err)
+
+	u, err := newPDFUpdate(buf.Bytes())
+	require.NoError(t, err)
+	rootID, _ := u.root()
+	catalog, err := u.dict(rootID)
+	require.NoError(t, err)
+	outlinesID, ok := refID(dictGet(catalog, "Outlines"))
+	require.True(t, ok)
+	outlines, err := u.dict(outlinesID)
+	require.NoError(t, err)
+
+	a := r.outlineRoots[0]
+	b := a.Children[0]
+	c := b.Children[0]
+	assert.Equal(t, ref(a.Obj.GetIndex()), string(dictGet(outlines, "First")))
+	assert.Equal(t, ref(a.Obj.GetIndex()), string(dictGet(outlines, "Last")), "last top-level bookmark")
+	assert.Equal(t, "3", string(dictGet(outlines, "Count")))
+
+	itemA, err := u.dict(a.Obj.GetIndex())
+	require.NoError(t, err)
+	assert.Equal(t, "2", string(dictGet(itemA, "Count")))
+	assert.Equal(t, ref(outlinesID), string(dictGet(itemA, "Parent")))
+	itemB, err := u.dict(b.Obj.GetIndex())
+	require.NoError(t, err)
+	assert.Equal(t, "1", string(dictGet(itemB, "Count")))
+	assert.Equal(t, ref(c.Obj.GetIndex()), string(dictGet(itemB, "Last")))
+	itemC, err := u.dict(c.Obj.GetIndex())
+	require.NoError(t, err)
+	assert.Nil(t, dictGet(itemC, "Count"))
+	assert.Equal(t, ref(b.Obj.GetIndex()), string(dictGet(itemC, "Parent")))
+}
+
+func TestRenderHTMLLikeToBuffer_BookmarksDisabled(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	r.pdf.SetNoCompression()
+
+	buf, err := r.RenderHTMLLikeToBuffer(`<h1>Title</h1><h2>Section</h2>`)
+	assert.NoError(t, err)
+	assert.Empty(t, r.outlineRoots)
+	assert.NotContains(t, buf.String(), "/Title <FEFF")
+}
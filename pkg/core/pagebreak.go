@@ -0,0 +1,220 @@
+// File: renderer/pagebreak.go
+package core
+
+import (
+	"log"
+	"strings"
+
+	"github.com/signintech/gopdf"
+	"golang.org/x/net/html"
+)
+
+// outOfFlow reports whether content is currently laid out outside the page
+// flow (a running header or footer, or a measurement). Such content never
+// breaks pages and does not add anchors, links, or bookmarks.
+func (r *Renderer) outOfFlow() bool {
+	return r.inRunningBlock || r.measuring
+}
+
+// paginated reports whether content is currently split across pages or
+// columns: always in the page flow and in multi-column sections (also while
+// they are measured), never in rows, running blocks, or single measured blocks.
+func (r *Renderer) paginated() bool {
+	return !r.inRow && (r.columns != nil || !r.outOfFlow())
+}
+
+// top returns where content starts in the current page or column.
+func (r *Renderer) top() float64 {
+	if r.columns != nil {
+		return r.columns.top
+	}
+	return r.pageTop
+}
+
+// bottom returns the lowest position content may reach in the current page or column.
+func (r *Renderer) bottom() float64 {
+	if r.columns != nil {
+		return r.columns.bottom()
+	}
+	return contentLimit
+}
+
+// breakPage starts a new page for a forced page break, or the next column
+// inside a multi-column section. Nothing happens when the current page or
+// column is still empty, so consecutive breaks do not produce blank pages.
+func (r *Renderer) breakPage() {
+	if !r.paginated() || r.y <= r.top() {
+		return
+	}
+	if r.columns != nil {
+		r.nextColumn()
+		return
+	}
+	r.flushPage()
+}
+
+// keepTogether moves n to the next page when it does not fit in the space left
+// on the current page but would fit on an empty one. Content taller than a page
+// is left to flow across pages.
+func (r *Renderer) keepTogether(n *html.Node) {
+	if !r.paginated() {
+		return
+	}
+	height := r.measureHeight(n, r.pageHeight())
+	if r.y+height > r.bottom() && r.top()+height <= r.bottom() {
+		r.breakPage()
+	}
+}
+
+// pageHeight returns a measurement limit just past the height of the current
+// page or column: content reaching it cannot be kept on one page anyway.
+func (r *Renderer) pageHeight() float64 {
+	return r.bottom() - r.top() + 1
+}
+
+// measureHeight returns the height n takes up when laid out, by walking it on
+// an off-screen PDF without breaking pages. With a positive limit, the
+// measurement stops once that height is reached (after the element, line or
+// table row that reaches it), and a height of at least limit is returned.
+func (r *Renderer) measureHeight(n *html.Node, limit float64) float64 {
+	scratch, err := r.scratchPDF()
+	if err != nil {
+		log.Println("Measuring skipped:", err)
+		return 0
+	}
+
+	pdf, y, columns, measuring, measureLimit := r.pdf, r.y, r.columns, r.measuring, r.measureLimit
+	r.pdf, r.y, r.columns, r.measuring, r.measureLimit = scratch, 0, nil, true, limit
+	defer func() {
+		r.pdf, r.y, r.columns, r.measuring, r.measureLimit = pdf, y, columns, measuring, measureLimit
+	}()
+
+	r.walk(n)
+	return r.y
+}
+
+// measured reports whether the measurement in progress has reached its limit,
+// so that laying out more would not change its outcome.
+func (r *Renderer) measured() bool {
+	return r.measuring && r.measureLimit > 0 && r.y >= r.measureLimit
+}
+
+// scratchPDF returns the off-screen PDF to measure on: the one of the
+// measurement in progress, if any, or a new one, so that what is drawn while
+// measuring is dropped with each measurement instead of piling up. The fonts
+// are parsed once and shared by all scratch PDFs of the renderer.
+func (r *Renderer) scratchPDF() (*gopdf.GoPdf, error) {
+	if r.measuring {
+		return r.pdf, nil
+	}
+	if r.scratchFonts == nil {
+		fonts, err := loadFontContainers()
+		if err != nil {
+			return nil, err
+		}
+		r.scratchFonts = fonts
+	}
+	pdf := &gopdf.GoPdf{}
+	pdf.Start(gopdf.Config{PageSize: *gopdf.PageSizeA4})
+	pdf.AddPage()
+	for _, fonts := range r.scratchFonts {
+		if err := pdf.AddTTFFontFromFontContainer("Arial", fonts); err != nil {
+			return nil, err
+		}
+	}
+	if err := pdf.SetFont("Arial", "", r.FontSize.P); err != nil {
+		return nil, err
+	}
+	return pdf, nil
+}
+
+// DefaultPagination returns the pagination used when none is configured: at
+// least two lines of a paragraph on either side of a page break, as in CSS.
+func DefaultPagination() Pagination {
+	return Pagination{Orphans: 2, Widows: 2}
+}
+
+// orphansAndWidows returns the minimum orphan and widow lines for a paragraph:
+// its own orphans/widows styles, falling back to the renderer's Pagination.
+func (r *Renderer) orphansAndWidows(n *html.Node) (int, int) {
+	orphans, widows := r.Pagination.Orphans, r.Pagination.Widows
+	if v, ok := styleInt(n, "orphans"); ok {
+		orphans = v
+	}
+	if v, ok := styleInt(n, "widows"); ok {
+		widows = v
+	}
+	return max(orphans, 1), max(widows, 1)
+}
+
+// linesBeforeBreak returns how many of the lines are drawn on the current page
+// before the first page break, given the minimum orphan and widow lines. It
+// returns len(lines) when all of them fit, and zero when the block has to start
+// on the next page.
+func (r *Renderer) linesBeforeBreak(lines []textLine, orphans, widows int) int {
+	if !r.paginated() {
+		return len(lines)
+	}
+	fit, y := 0, r.y
+	for _, line := range lines {
+		if y+line.height > r.bottom() {
+			break
+		}
+		y += line.height
+		fit++
+	}
+	if fit == len(lines) {
+		return fit
+	}
+	fit = min(fit, len(lines)-widows)
+	if fit < orphans {
+		return 0
+	}
+	return fit
+}
+
+// keepWithNext moves n to the next page when it would end up at the bottom of
+// the current page without the start of the element that follows it.
+func (r *Renderer) keepWithNext(n *html.Node) {
+	if !r.paginated() {
+		return
+	}
+	height := r.measureHeight(n, r.pageHeight()) + r.nextBlockHeight(n)
+	if r.y+height > r.bottom() && r.top()+height <= r.bottom() {
+		r.breakPage()
+	}
+}
+
+// nextBlockHeight returns how much of the element following n has to fit on
+// the same page as n: its first few lines (as many as the minimum orphan
+// lines), or all of it and whatever it is kept with in turn if it is another
+// element kept with its next, such as a subheading. Only the start of the
+// element is measured, so a long table or section is not laid out twice.
+func (r *Renderer) nextBlockHeight(n *html.Node) float64 {
+	next := nextElement(n)
+	if next == nil {
+		return 0
+	}
+	if keepsWithNext(next) {
+		return r.measureHeight(next, r.pageHeight()) + r.nextBlockHeight(next)
+	}
+	lineHeight := r.FontSize.P * 4 / 3
+	limit := float64(max(r.Pagination.Orphans, 1))*lineHeight + 4
+	return min(r.measureHeight(next, limit), limit)
+}
+
+// nextElement returns the element sibling following n, skipping whitespace
+// and comments, or nil when n is followed by text or nothing.
+func nextElement(n *html.Node) *html.Node {
+	for c := n.NextSibling; c != nil; c = c.NextSibling {
+		switch c.Type {
+		case html.ElementNode:
+			return c
+		case html.TextNode:
+			if strings.TrimSpace(c.Data) != "" {
+				return nil
+			}
+		}
+	}
+	return nil
+}
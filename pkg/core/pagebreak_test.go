@@ -0,0 +1,235 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/html"
+)
+
+func TestRenderHTMLLikeToBuffer_PageBreakElement(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+
+	_, err := r.RenderHTMLLikeToBuffer(`<pagebreak/><p>One</p><pagebreak/><pagebreak/><p>Two</p>`)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, r.pageNumber, "breaks on an empty page are ignored")
+}
+
+func TestRenderHTMLLikeToBuffer_PageBreakStyles(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+
+	_, err := r.RenderHTMLLikeToBuffer(`
+		<p>Intro</p>
+		<div style="page-break-before: always"><p>Chart</p></div>
+		<p style="page-break-after: always">Last on page</p>
+		<h2 id="next">Next</h2>`)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, r.pageNumber)
+	assert.Equal(t, 3, r.anchorPages["next"])
+}
+
+func TestRenderHTMLLikeToBuffer_PageBreakInsideAvoid(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+
+	var body strings.Builder
+	for i := 0; i < 33; i++ {
+		body.WriteString("<p>Filler</p>")
+	}
+	body.WriteString(`<div id="section" style="page-break-inside: avoid"><h2>Heading</h2>`)
+	for i := 0; i < 5; i++ {
+		body.WriteString("<p>Kept with the heading</p>")
+	}
+	body.WriteString(`</div>`)
+
+	_, err := r.RenderHTMLLikeToBuffer(body.String())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, r.pageNumber)
+	assert.Equal(t, 2, r.anchorPages["section"], "the whole section moves to the next page")
+}
+
+func TestMeasureHeight(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	doc, _ := html.Parse(strings.NewReader(`<div id="m"><p>One</p><p>Two</p></div>`))
+
+	height := r.measureHeight(findNode(doc, "div"), 0)
+
+	assert.InDelta(t, 2*(r.FontSize.P*4/3+4), height, 0.01)
+	assert.Equal(t, 50.0, r.y, "measuring does not move the layout position")
+	assert.Empty(t, r.pendingAnchors, "measuring does not place anchors")
+}
+
+func TestScratchPDF(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+
+	first, err := r.scratchPDF()
+	require.NoError(t, err)
+	second, err := r.scratchPDF()
+	require.NoError(t, err)
+	assert.NotSame(t, first, second, "each measurement starts on an empty scratch PDF")
+	width, err := second.MeasureTextWidth("Text")
+	require.NoError(t, err)
+	assert.Greater(t, width, 0.0)
+
+	r.pdf, r.measuring = first, true
+	nested, err := r.scratchPDF()
+	require.NoError(t, err)
+	assert.Same(t, first, nested, "nested measurements share the scratch PDF")
+}
+
+func TestLinesBeforeBreak(t *testing.T) {
+	lines := make([]textLine, 5)
+	for i := range lines {
+		lines[i].height = 20
+	}
+	r := &Renderer{}
+
+	r.y = 50
+	assert.Equal(t, 5, r.linesBeforeBreak(lines, 2, 2), "everything fits")
+
+	r.y = contentLimit - 60
+	assert.Equal(t, 3, r.linesBeforeBreak(lines, 2, 2))
+
+	r.y = contentLimit - 80
+	assert.Equal(t, 3, r.linesBeforeBreak(lines, 2, 2), "two widow lines are carried over")
+
+	r.y = contentLimit - 20
+	assert.Equal(t, 0, r.linesBeforeBreak(lines, 2, 2), "a single orphan line moves the paragraph")
+	assert.Equal(t, 1, r.linesBeforeBreak(lines, 1, 1))
+}
+
+func TestOrphansAndWidows(t *testing.T) {
+	doc, _ := html.Parse(strings.NewReader(`<p style="orphans: 3; widows: 0">x</p>`))
+	r := &Renderer{Pagination: DefaultPagination()}
+
+	orphans, widows := r.orphansAndWidows(findNode(doc, "p"))
+	assert.Equal(t, 3, orphans)
+	assert.Equal(t, 1, widows, "at least one line is always kept")
+}
+
+func TestKeepsWithNext(t *testing.T) {
+	doc, _ := html.Parse(strings.NewReader(`
+		<h2>Heading</h2>
+		<h3 style="page-break-after: auto">Loose</h3>
+		<p style="break-after: avoid">Caption</p>
+		<p>Body</p>`))
+
+	assert.True(t, keepsWithNext(findNode(doc, "h2")))
+	assert.False(t, keepsWithNext(findNode(doc, "h3")))
+
+	caption := nextElement(findNode(doc, "h3"))
+	assert.True(t, keepsWithNext(caption))
+	assert.False(t, keepsWithNext(nextElement(caption)))
+}
+
+func TestRenderHTMLLikeToBuffer_HeadingKeptWithNext(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+
+	var body strings.Builder
+	for i := 0; i < 34; i++ {
+		body.WriteString("<p>Filler</p>")
+	}
+	body.WriteString(`<h2 id="heading">Heading</h2><p id="body">Body text</p>`)
+
+	_, err := r.RenderHTMLLikeToBuffer(body.String())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, r.anchorPages["heading"], "the heading is not left at the bottom of page 1")
+	assert.Equal(t, 2, r.anchorPages["body"])
+}
+
+func TestRenderHTMLLikeToBuffer_ParagraphOrphans(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	r.Pagination.Orphans = 3
+
+	var body strings.Builder
+	for i := 0; i < 34; i++ {
+		body.WriteString("<p>Filler</p>")
+	}
+	body.WriteString(`<p id="long">` + strings.Repeat("Lorem ipsum dolor sit amet. ", 40) + `</p>`)
+
+	_, err := r.RenderHTMLLikeToBuffer(body.String())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, r.anchorPages["long"], "two lines are fewer than the three orphan lines required")
+}
+
+func TestNextBlockHeight_MeasuresOnlyTheStart(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	rows := strings.Repeat("<tr><td>Cell</td><td>Cell</td></tr>", 500)
+	doc, _ := html.Parse(strings.NewReader(`<h2>Table</h2><table>` + rows + `</table>` +
+		`<h2>Section</h2><div>` + strings.Repeat("<p>Paragraph</p>", 500) + `</div>`))
+	headings := findElements(doc, "h2")
+	limit := float64(r.Pagination.Orphans)*r.FontSize.P*4/3 + 4
+
+	for _, h := range headings {
+		assert.InDelta(t, limit, r.nextBlockHeight(h), 0.01)
+		// The measurement stops after the row or paragraph reaching the limit.
+		assert.Less(t, r.measureHeight(nextElement(h), limit), 2*limit)
+	}
+	assert.Greater(t, r.measureHeight(nextElement(headings[0]), 0), 500*14.0, "unlimited measurements lay out everything")
+}
+
+func TestRenderHTMLLikeToBuffer_WidowsAtEveryBreak(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	paragraph := func(lines int) string {
+		var b strings.Builder
+		for i := 0; i < lines; i++ {
+			if i > 0 {
+				b.WriteString("<br>")
+			}
+			fmt.Fprintf(&b, "Row%d;", i)
+		}
+		return "<p>" + b.String() + "</p>"
+	}
+	row := regexp.MustCompile(`Row\d+;`)
+
+	// Lines of a full page, counted on the second page of a long paragraph.
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	buf, err := r.RenderHTMLLikeToBuffer(paragraph(200))
+	require.NoError(t, err)
+	perPage := len(row.FindAllString(pageText(t, buf.Bytes())[1], -1))
+	require.Greater(t, perPage, 10)
+
+	// Paragraphs ending just past the second page break leave at least two
+	// lines on the third page.
+	for lines := 2*perPage - 2; lines <= 2*perPage+2; lines++ {
+		r, _ := NewRenderer(defaultFontSizes(), true)
+		buf, err := r.RenderHTMLLikeToBuffer(paragraph(lines))
+		require.NoError(t, err)
+		total := 0
+		for i, text := range pageText(t, buf.Bytes()) {
+			count := len(row.FindAllString(text, -1))
+			assert.GreaterOrEqual(t, count, 2, "%d lines: page %d", lines, i+1)
+			total += count
+		}
+		assert.Equal(t, lines, total)
+	}
+}
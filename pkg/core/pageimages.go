@@ -0,0 +1,109 @@
+// File: renderer/pageimages.go
+package core
+
+import (
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"log"
+	"os"
+
+	"github.com/signintech/gopdf"
+)
+
+// DefaultBackgroundImageOptions returns the background image placement used
+// when none is configured: stretched across the whole page.
+func DefaultBackgroundImageOptions() PageImageOptions {
+	return PageImageOptions{Width: 595.28, Height: 841.89, Fit: ImageFitStretch}
+}
+
+// DefaultHeaderImageOptions returns the header image placement used when none
+// is configured: a 495×40 banner at the top of the page.
+func DefaultHeaderImageOptions() PageImageOptions {
+	return PageImageOptions{X: 50, Y: 20, Width: 495, Height: 40, Fit: ImageFitStretch}
+}
+
+// DefaultFooterImageOptions returns the footer image placement used when none
+// is configured: a 495×30 banner at the bottom of the page.
+func DefaultFooterImageOptions() PageImageOptions {
+	return PageImageOptions{X: 50, Y: 800, Width: 495, Height: 30, Fit: ImageFitStretch}
+}
+
+// onPage reports whether the image is drawn on the given page.
+func (o PageImageOptions) onPage(page int) bool {
+	if page == 1 {
+		return !o.SkipFirstPage
+	}
+	return !o.FirstPageOnly
+}
+
+// drawPageImage draws the image at path into the box described by opts and
+// returns the bottom edge of the drawn image, or zero when it is not drawn on
+// the current page.
+func (r *Renderer) drawPageImage(path string, opts PageImageOptions) float64 {
+	if path == "" || !opts.onPage(r.pageNumber) || opts.Width <= 0 || opts.Height <= 0 {
+		return 0
+	}
+
+	imageOpts := gopdf.ImageOptions{
+		X:    opts.X,
+		Y:    opts.Y,
+		Rect: &gopdf.Rect{W: opts.Width, H: opts.Height},
+	}
+	if opts.Fit == ImageFitContain || opts.Fit == ImageFitCover {
+		if w, h, err := imageFileSize(path); err == nil && w > 0 && h > 0 {
+			scale := min(opts.Width/w, opts.Height/h)
+			if opts.Fit == ImageFitCover {
+				scale = max(opts.Width/w, opts.Height/h)
+			}
+			w, h = w*scale, h*scale
+			imageOpts.Rect = &gopdf.Rect{W: w, H: h}
+			if opts.Fit == ImageFitContain {
+				imageOpts.X += (opts.Width - w) / 2
+				imageOpts.Y += (opts.Height - h) / 2
+			} else {
+				imageOpts.Crop = &gopdf.CropOptions{
+					X:      (w - opts.Width) / 2,
+					Y:      (h - opts.Height) / 2,
+					Width:  opts.Width,
+					Height: opts.Height,
+				}
+			}
+		}
+	}
+	if opts.Opacity > 0 && opts.Opacity < 1 {
+		transparency, err := gopdf.NewTransparency(opts.Opacity, "")
+		if err == nil {
+			imageOpts.Transparency = &transparency
+		}
+	}
+
+	holder, err := gopdf.ImageHolderByPath(path)
+	if err != nil {
+		log.Println("Page image skipped:", err)
+		return 0
+	}
+	if err := r.pdf.ImageByHolderWithOptions(holder, imageOpts); err != nil {
+		log.Println("Page image render failed:", err)
+		return 0
+	}
+	if imageOpts.Crop != nil {
+		return opts.Y + opts.Height
+	}
+	return imageOpts.Y + imageOpts.Rect.H
+}
+
+// imageFileSize returns the pixel dimensions of an image file.
+func imageFileSize(path string) (float64, float64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	cfg, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return 0, 0, err
+	}
+	return float64(cfg.Width), float64(cfg.Height), nil
+}
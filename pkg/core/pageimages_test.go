@@ -0,0 +1,82 @@
+package core
+
+import (
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeTestPNG writes a blank PNG of the given size and returns its path.
+func writeTestPNG(t *testing.T, width, height int) string {
+	path := filepath.Join(t.TempDir(), "image.png")
+	f, err := os.Create(path)
+	assert.NoError(t, err)
+	defer f.Close()
+	assert.NoError(t, png.Encode(f, image.NewRGBA(image.Rect(0, 0, width, height))))
+	return path
+}
+
+func TestPageImageOptions_OnPage(t *testing.T) {
+	assert.True(t, PageImageOptions{}.onPage(1))
+	assert.True(t, PageImageOptions{}.onPage(2))
+	assert.False(t, PageImageOptions{SkipFirstPage: true}.onPage(1))
+	assert.True(t, PageImageOptions{SkipFirstPage: true}.onPage(2))
+	assert.True(t, PageImageOptions{FirstPageOnly: true}.onPage(1))
+	assert.False(t, PageImageOptions{FirstPageOnly: true}.onPage(3))
+}
+
+func TestDrawPageImage_Fit(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	path := writeTestPNG(t, 400, 100)
+	box := PageImageOptions{X: 50, Y: 20, Width: 200, Height: 100}
+
+	box.Fit = ImageFitStretch
+	assert.Equal(t, 120.0, r.drawPageImage(path, box))
+
+	box.Fit = ImageFitContain
+	assert.Equal(t, 95.0, r.drawPageImage(path, box), "a 4:1 image is letterboxed and centered")
+
+	box.Fit = ImageFitCover
+	assert.Equal(t, 120.0, r.drawPageImage(path, box), "the overflow is cropped to the box")
+
+	box.FirstPageOnly = true
+	r.pageNumber = 2
+	assert.Equal(t, 0.0, r.drawPageImage(path, box))
+}
+
+func TestHeaderImageDecorator_ContentBelowHeader(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	r.headerImg = writeTestPNG(t, 495, 80)
+
+	r.startPage()
+	assert.Equal(t, 100.0, r.y, "the default header band")
+	assert.Equal(t, r.y, r.pageTop)
+
+	r.HeaderImageOptions.Height = 80
+	r.HeaderImageOptions.Fit = ImageFitContain
+	r.startPage()
+	assert.Equal(t, pageMargin+80+10, r.y, "the band grows with the image")
+
+	r.HeaderImageOptions.Y = 200
+	r.HeaderImageOptions.Height = 40
+	r.startPage()
+	assert.Equal(t, 200+40+10.0, r.y, "content starts below the image wherever it is placed")
+
+	r.HeaderImageOptions.Height = 200
+	r.startPage()
+	assert.Equal(t, 200+(200-80)/2+80+10.0, r.y, "a contained image is centered in its box")
+
+	r.HeaderImageOptions.SkipFirstPage = true
+	r.startPage()
+	assert.Equal(t, pageMargin, r.y, "no offset when the header is not drawn")
+}
@@ -0,0 +1,113 @@
+// File: renderer/pagenumbers.go
+package core
+
+import (
+	"strconv"
+	"strings"
+)
+
+// DefaultPageNumberFormat returns the page number format used when none is
+// configured: "Page {page}" in decimal, right-aligned in the footer.
+func DefaultPageNumberFormat() PageNumberFormat {
+	return PageNumberFormat{
+		Template: "Page {page}",
+		Style:    PageNumberDecimal,
+		Position: PageNumberFooter,
+		Align:    AlignRight,
+		StartAt:  1,
+	}
+}
+
+// pageLabel returns the page number displayed for a physical page (1-based),
+// taking front matter, the starting offset, and the numbering style into account.
+func (f PageNumberFormat) pageLabel(physical int) string {
+	if physical <= f.FrontMatterPages {
+		style := f.FrontMatterStyle
+		if style == "" {
+			style = PageNumberRomanLower
+		}
+		return formatPageNumber(physical, style)
+	}
+	start := f.StartAt
+	if start == 0 {
+		start = 1
+	}
+	return formatPageNumber(physical-f.FrontMatterPages+start-1, f.Style)
+}
+
+// render substitutes {page} and {total} in the template for a physical page
+// out of totalPages. A set Total replaces the total, e.g. for a report that is
+// merged into a longer document.
+func (f PageNumberFormat) render(physical, totalPages int) string {
+	template := f.Template
+	if template == "" {
+		template = "Page {page}"
+	}
+	total := f.pageLabel(totalPages)
+	if f.Total > 0 {
+		total = formatPageNumber(f.Total, f.Style)
+	}
+	return strings.NewReplacer(
+		"{page}", f.pageLabel(physical),
+		"{total}", total,
+	).Replace(template)
+}
+
+// formatPageNumber formats n in the given numbering style. Roman numerals are
+// only defined for 1–3999; other values fall back to decimal.
+func formatPageNumber(n int, style PageNumberStyle) string {
+	switch style {
+	case PageNumberRomanLower:
+		return strings.ToLower(toRoman(n))
+	case PageNumberRomanUpper:
+		return toRoman(n)
+	default:
+		return strconv.Itoa(n)
+	}
+}
+
+// toRoman converts n to upper-case Roman numerals, or to decimal when n is outside 1–3999.
+func toRoman(n int) string {
+	if n < 1 || n > 3999 {
+		return strconv.Itoa(n)
+	}
+	values := []int{1000, 900, 500, 400, 100, 90, 50, 40, 10, 9, 5, 4, 1}
+	symbols := []string{"M", "CM", "D", "CD", "C", "XC", "L", "XL", "X", "IX", "V", "IV", "I"}
+
+	var b strings.Builder
+	for i, v := range values {
+		for n >= v {
+			b.WriteString(symbols[i])
+			n -= v
+		}
+	}
+	return b.String()
+}
+
+// drawPageNumber draws the number of the given page, out of total pages, on
+// the current page when page numbers are enabled.
+func (r *Renderer) drawPageNumber(page, total int) {
+	if !r.showPageNumber {
+		return
+	}
+	format := r.PageNumbers
+	y := 820.0
+	if format.Position == PageNumberHeader {
+		y = 20
+	}
+
+	_ = r.pdf.SetFont("Arial", "", r.FontSize.Footer)
+	text := format.render(page, total)
+	width, _ := r.pdf.MeasureTextWidth(text)
+
+	var x float64
+	switch format.Align {
+	case AlignLeft:
+		x = 50
+	case AlignCenter:
+		x = (595.28 - width) / 2
+	default:
+		x = 545 - width
+	}
+	r.drawText(text, x, y)
+}
@@ -0,0 +1,56 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToRoman(t *testing.T) {
+	assert.Equal(t, "I", toRoman(1))
+	assert.Equal(t, "IV", toRoman(4))
+	assert.Equal(t, "XIV", toRoman(14))
+	assert.Equal(t, "MCMXCIX", toRoman(1999))
+	assert.Equal(t, "0", toRoman(0), "values without a Roman numeral fall back to decimal")
+}
+
+func TestPageNumberFormat_Render(t *testing.T) {
+	format := PageNumberFormat{Template: "Page {page} of {total}"}
+	assert.Equal(t, "Page 2 of 5", format.render(2, 5))
+
+	format = PageNumberFormat{FrontMatterPages: 2, StartAt: 1}
+	assert.Equal(t, "Page i", format.render(1, 5), "front matter defaults to lower-case roman")
+	assert.Equal(t, "Page ii", format.render(2, 5))
+	assert.Equal(t, "Page 1", format.render(3, 5), "main numbering restarts after the front matter")
+
+	format = PageNumberFormat{Template: "{page}/{total}", StartAt: 10, Style: PageNumberRomanUpper}
+	assert.Equal(t, "X/XII", format.render(1, 3))
+
+	format = PageNumberFormat{Template: "{page} of {total}", StartAt: 4, Total: 9}
+	assert.Equal(t, "5 of 9", format.render(2, 3), "a set total replaces the report's own")
+}
+
+func TestRenderHTMLLikeToBuffer_PageXOfY(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	r.PageNumbers.Template = "Page {page} of {total}"
+
+	var body strings.Builder
+	for i := 0; i < 80; i++ {
+		body.WriteString("<p>Lorem ipsum dolor sit amet, consectetur adipiscing elit.</p>")
+	}
+	buf, err := r.RenderHTMLLikeToBuffer(body.String())
+	require.NoError(t, err)
+	total := r.pageNumber
+	assert.Greater(t, total, 1)
+
+	pages := pageText(t, buf.Bytes())
+	require.Len(t, pages, total)
+	assert.Contains(t, pages[0], fmt.Sprintf("Page 1 of %d", total))
+	assert.Contains(t, pages[total-1], fmt.Sprintf("Page %d of %d", total, total))
+}
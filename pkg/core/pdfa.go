@@ -0,0 +1,148 @@
+// File: renderer/pdfa.go
+package core
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrPDFANonCompliant is returned, wrapped with the reason, when a document
+// rendered with PDFA set cannot conform to PDF/A-2b.
+var ErrPDFANonCompliant = errors.New("document cannot be PDF/A-2b compliant")
+
+// srgbOutputCondition identifies the sRGB output intent of PDF/A documents.
+const srgbOutputCondition = "sRGB IEC61966-2.1"
+
+// checkPDFA reports settings that rule out PDF/A before anything is rendered.
+func (r *Renderer) checkPDFA() error {
+	if r.Protection != nil {
+		return fmt.Errorf("%w: encryption is not allowed", ErrPDFANonCompliant)
+	}
+	if len(r.Attachments) > 0 {
+		return fmt.Errorf("%w: PDF/A-2 only allows embedding PDF/A documents", ErrPDFANonCompliant)
+	}
+	return nil
+}
+
+// makePDFA turns a PDF written by gopdf into a PDF/A-2b document through an
+// incremental update. All fonts are already embedded; the update adds the XMP
+// metadata matching meta and an sRGB output intent to the catalog, gives the
+// document an /ID and an indirect information dictionary, marks annotations as
+// printable, and declares the identity glyph mapping of the embedded fonts.
+//
+// Transparency is allowed in PDF/A-2 once an output intent is present, so
+// watermarks and faded images are kept. CMYK images cannot be represented with
+// the sRGB output intent and make the document non-compliant.
+func makePDFA(pdf []byte, meta Metadata) ([]byte, error) {
+	if bytes.Contains(pdf, []byte("/DeviceCMYK")) {
+		return nil, fmt.Errorf("%w: CMYK images cannot be used with the sRGB output intent", ErrPDFANonCompliant)
+	}
+	if bytes.Contains(pdf, []byte("/Encrypt")) {
+		return nil, fmt.Errorf("%w: encryption is not allowed", ErrPDFANonCompliant)
+	}
+
+	u, err := newPDFUpdate(pdf)
+	if err != nil {
+		return nil, err
+	}
+	rootID, err := u.root()
+	if err != nil {
+		return nil, err
+	}
+	catalog, err := u.dict(rootID)
+	if err != nil {
+		return nil, err
+	}
+
+	metadataID := u.add(streamObject([]dictEntry{
+		{key: "Type", value: []byte("/Metadata")},
+		{key: "Subtype", value: []byte("/XML")},
+	}, xmpMetadata(meta)))
+	profileID := u.add(streamObject([]dictEntry{{key: "N", value: []byte("3")}}, srgbICCProfile()))
+	intent := formatDict([]dictEntry{
+		{key: "Type", value: []byte("/OutputIntent")},
+		{key: "S", value: []byte("/GTS_PDFA1")},
+		{key: "OutputConditionIdentifier", value: []byte("(" + srgbOutputCondition + ")")},
+		{key: "Info", value: []byte("(" + srgbOutputCondition + ")")},
+		{key: "DestOutputProfile", value: []byte(ref(profileID))},
+	})
+	catalog = dictSet(catalog, "Metadata", ref(metadataID))
+	catalog = dictSet(catalog, "OutputIntents", "["+string(intent)+"]")
+	u.set(rootID, formatDict(catalog))
+
+	for _, id := range u.ids() {
+		dict, err := u.dict(id)
+		if err != nil {
+			continue
+		}
+		switch {
+		case string(dictGet(dict, "Type")) == "/Annot" && dictGet(dict, "F") == nil:
+			u.set(id, formatDict(dictSet(dict, "F", "4")))
+		case string(dictGet(dict, "Subtype")) == "/CIDFontType2" && dictGet(dict, "CIDToGIDMap") == nil:
+			u.set(id, formatDict(dictSet(dict, "CIDToGIDMap", "/Identity")))
+		}
+	}
+
+	// gopdf writes the information dictionary inline in the trailer, where it
+	// must be an indirect reference.
+	if info := dictGet(u.trailer, "Info"); bytes.HasPrefix(info, []byte("<<")) {
+		u.trailer = dictSet(u.trailer, "Info", ref(u.add(info)))
+	}
+	if dictGet(u.trailer, "ID") == nil {
+		sum := md5.Sum(pdf)
+		u.trailer = dictSet(u.trailer, "ID", fmt.Sprintf("[<%X> <%X>]", sum, sum))
+	}
+	return u.bytes(), nil
+}
+
+// xmpMetadata returns the XMP packet identifying a PDF/A-2b document, with the
+// same document properties as its information dictionary.
+func xmpMetadata(meta Metadata) []byte {
+	esc := func(s string) string {
+		var b strings.Builder
+		_ = xml.EscapeText(&b, []byte(s))
+		return b.String()
+	}
+
+	var b strings.Builder
+	b.WriteString("<?xpacket begin=\"\uFEFF\" id=\"W5M0MpCehiHzreSzNTczkc9d\"?>\n")
+	b.WriteString("<x:xmpmeta xmlns:x=\"adobe:ns:meta/\">\n")
+	b.WriteString(" <rdf:RDF xmlns:rdf=\"http://www.w3.org/1999/02/22-rdf-syntax-ns#\">\n")
+	b.WriteString("  <rdf:Description rdf:about=\"\"")
+	b.WriteString(" xmlns:pdfaid=\"http://www.aiim.org/pdfa/ns/id/\"")
+	b.WriteString(" xmlns:dc=\"http://purl.org/dc/elements/1.1/\"")
+	b.WriteString(" xmlns:xmp=\"http://ns.adobe.com/xap/1.0/\"")
+	b.WriteString(" xmlns:pdf=\"http://ns.adobe.com/pdf/1.3/\">\n")
+	b.WriteString("   <pdfaid:part>2</pdfaid:part>\n")
+	b.WriteString("   <pdfaid:conformance>B</pdfaid:conformance>\n")
+	if meta.Title != "" {
+		fmt.Fprintf(&b, "   <dc:title><rdf:Alt><rdf:li xml:lang=\"x-default\">%s</rdf:li></rdf:Alt></dc:title>\n", esc(meta.Title))
+	}
+	if meta.Author != "" {
+		fmt.Fprintf(&b, "   <dc:creator><rdf:Seq><rdf:li>%s</rdf:li></rdf:Seq></dc:creator>\n", esc(meta.Author))
+	}
+	if meta.Subject != "" {
+		fmt.Fprintf(&b, "   <dc:description><rdf:Alt><rdf:li xml:lang=\"x-default\">%s</rdf:li></rdf:Alt></dc:description>\n", esc(meta.Subject))
+	}
+	if meta.Keywords != "" {
+		fmt.Fprintf(&b, "   <pdf:Keywords>%s</pdf:Keywords>\n", esc(meta.Keywords))
+	}
+	if meta.Producer != "" {
+		fmt.Fprintf(&b, "   <pdf:Producer>%s</pdf:Producer>\n", esc(meta.Producer))
+	}
+	if meta.Creator != "" {
+		fmt.Fprintf(&b, "   <xmp:CreatorTool>%s</xmp:CreatorTool>\n", esc(meta.Creator))
+	}
+	if !meta.CreationDate.IsZero() {
+		fmt.Fprintf(&b, "   <xmp:CreateDate>%s</xmp:CreateDate>\n", meta.CreationDate.Format("2006-01-02T15:04:05-07:00"))
+	}
+	b.WriteString("  </rdf:Description>\n")
+	b.WriteString(" </rdf:RDF>\n")
+	b.WriteString("</x:xmpmeta>\n")
+	b.WriteString("<?xpacket end=\"w\"?>")
+	return []byte(b.String())
+}
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderHTMLLikeToBuffer_PDFA(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	r.PDFA = true
+	r.Metadata.Author = "Archive & Records"
+
+	buf, err := r.RenderHTMLLikeToBuffer(`<title>Ledger</title><p><a href="https://example.com">Link</a></p>`)
+	assert.NoError(t, err)
+
+	pdf := buf.String()
+	assert.Contains(t, pdf, "<pdfaid:part>2</pdfaid:part>")
+	assert.Contains(t, pdf, "<dc:creator><rdf:Seq><rdf:li>Archive &amp; Records</rdf:li></rdf:Seq></dc:creator>")
+	assert.Contains(t, pdf, "<rdf:li xml:lang=\"x-default\">Ledger</rdf:li>")
+	assert.Contains(t, pdf, "/S /GTS_PDFA1")
+	assert.Contains(t, pdf, "/CIDToGIDMap /Identity")
+
+	u, err := newPDFUpdate(buf.Bytes())
+	assert.NoError(t, err)
+	assert.NotNil(t, dictGet(u.trailer, "ID"))
+	_, ok := refID(dictGet(u.trailer, "Info"))
+	assert.True(t, ok, "the information dictionary is an indirect object")
+
+	rootID, _ := u.root()
+	catalog, err := u.dict(rootID)
+	assert.NoError(t, err)
+	assert.NotNil(t, dictGet(catalog, "Metadata"))
+	assert.NotNil(t, dictGet(catalog, "OutputIntents"))
+
+	annotations := 0
+	for _, id := range u.ids() {
+		if dict, err := u.dict(id); err == nil && string(dictGet(dict, "Type")) == "/Annot" {
+			annotations++
+			assert.Equal(t, "4", string(dictGet(dict, "F")), "annotations are printable")
+		}
+	}
+	assert.Equal(t, 1, annotations)
+}
+
+func TestRenderHTMLLikeToBuffer_PDFACreationDate(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	r.PDFA = true
+	r.Metadata.CreationDate = time.Date(2025, 7, 1, 9, 30, 0, 0, time.FixedZone("IST", 5*3600+1800))
+
+	buf, err := r.RenderHTMLLikeToBuffer(`<p>Body</p>`)
+	require.NoError(t, err)
+
+	u, err := newPDFUpdate(buf.Bytes())
+	require.NoError(t, err)
+	infoID, ok := refID(dictGet(u.trailer, "Info"))
+	require.True(t, ok)
+	info, err := u.dict(infoID)
+	require.NoError(t, err)
+	assert.Equal(t, "(D:20250701040000+00'00')", string(dictGet(info, "CreationDate")))
+	assert.Contains(t, buf.String(), "<xmp:CreateDate>2025-07-01T04:00:00+00:00</xmp:CreateDate>")
+}
+
+func TestRenderHTMLLikeToBuffer_PDFARejectsEncryption(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	r.PDFA = true
+	r.Protection = &Protection{UserPassword: "secret"}
+
+	_, err := r.RenderHTMLLikeToBuffer(`<p>Body</p>`)
+	assert.ErrorIs(t, err, ErrPDFANonCompliant)
+	assert.ErrorContains(t, err, "encryption")
+}
+
+func TestMakePDFA_RejectsCMYK(t *testing.T) {
+	_, err := makePDFA([]byte("%PDF-1.7\n1 0 obj\n<< /ColorSpace /DeviceCMYK >>\nendobj\n"), Metadata{})
+	assert.ErrorIs(t, err, ErrPDFANonCompliant)
+	assert.ErrorContains(t, err, "CMYK")
+}
+
+func TestSRGBICCProfile(t *testing.T) {
+	profile := srgbICCProfile()
+	assert.Equal(t, uint32(len(profile)), binary.BigEndian.Uint32(profile))
+	assert.Equal(t, "mntrRGB XYZ ", string(profile[12:24]))
+	assert.Equal(t, "acsp", string(profile[36:40]))
+	assert.Equal(t, uint32(9), binary.BigEndian.Uint32(profile[128:]), "tag count")
+}
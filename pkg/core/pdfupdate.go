@@ -0,0 +1,781 @@
+// File: renderer/pdfupdate.go
+package core
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// pdfUpdate appends an incremental update to a finished PDF. New and replaced
+// objects are written after the original bytes, followed by their own
+// cross-reference section and a trailer that points back at the previous one,
+// so nothing that was already written moves. This is how features gopdf has no
+// support for (archival metadata, signatures, form fields, attachments) are
+// added to the document once it has been rendered.
+//
+// Only classic cross-reference tables, as written by gopdf and by pdfUpdate
+// itself, are supported.
+type pdfUpdate struct {
+	base    []byte         // Document being updated.
+	offsets map[int]int    // Byte offset of every object in base.
+	prev    int            // Offset of the last cross-reference section of base.
+	size    int            // Next free object number.
+	trailer []dictEntry    // Trailer of the update, starting from the last one of base.
+	objects map[int][]byte // New and replaced objects by number.
+}
+
+// dictEntry is a key and raw value of a PDF dictionary.
+type dictEntry struct {
+	key   string
+	value []byte
+}
+
+// newPDFUpdate reads the cross-reference tables and trailer of pdf.
+func newPDFUpdate(pdf []byte) (*pdfUpdate, error) {
+	i := bytes.LastIndex(pdf, []byte("startxref"))
+	if i < 0 {
+		return nil, errors.New("PDF has no startxref")
+	}
+	fields := bytes.Fields(pdf[i+len("startxref"):])
+	if len(fields) == 0 {
+		return nil, errors.New("PDF has no cross-reference offset")
+	}
+	prev, err := strconv.Atoi(string(fields[0]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid cross-reference offset: %w", err)
+	}
+
+	u := &pdfUpdate{base: pdf, offsets: map[int]int{}, prev: prev, objects: map[int][]byte{}}
+	seen := map[int]bool{}
+	for offset, first := prev, true; offset >= 0; first = false {
+		if seen[offset] {
+			return nil, errors.New("cyclic /Prev chain in cross-reference sections")
+		}
+		seen[offset] = true
+		trailer, err := u.readXref(offset)
+		if err != nil {
+			return nil, err
+		}
+		if first {
+			u.trailer = trailer
+			size, _ := strconv.Atoi(string(dictGet(trailer, "Size")))
+			u.size = size
+		}
+		offset = -1
+		if p := dictGet(trailer, "Prev"); p != nil {
+			if offset, err = strconv.Atoi(string(p)); err != nil || offset < 0 {
+				return nil, fmt.Errorf("invalid /Prev %s", p)
+			}
+		}
+	}
+	if u.size == 0 {
+		return nil, errors.New("PDF trailer has no /Size")
+	}
+	return u, nil
+}
+
+// readXref reads the cross-reference section at offset, keeping entries of
+// newer sections that were already read, and returns its trailer.
+func (u *pdfUpdate) readXref(offset int) ([]dictEntry, error) {
+	if offset < 0 || offset >= len(u.base) || !bytes.HasPrefix(u.base[offset:], []byte("xref")) {
+		return nil, errors.New("cross-reference streams are not supported")
+	}
+	end := bytes.Index(u.base[offset:], []byte("trailer"))
+	if end < 0 {
+		return nil, errors.New("PDF has no trailer")
+	}
+	fields := bytes.Fields(u.base[offset+len("xref") : offset+end])
+	for len(fields) >= 2 {
+		start, err1 := strconv.Atoi(string(fields[0]))
+		count, err2 := strconv.Atoi(string(fields[1]))
+		if err1 != nil || err2 != nil || start < 0 || count < 0 || count > (len(fields)-2)/3 {
+			return nil, errors.New("malformed cross-reference table")
+		}
+		for k := 0; k < count; k++ {
+			entry := fields[2+3*k:]
+			if string(entry[2]) != "n" {
+				continue
+			}
+			off, err := strconv.Atoi(string(entry[0]))
+			if err != nil || off < 0 || off >= len(u.base) {
+				return nil, fmt.Errorf("object %d has an invalid offset", start+k)
+			}
+			if _, ok := u.offsets[start+k]; !ok {
+				u.offsets[start+k] = off
+			}
+		}
+		fields = fields[2+3*count:]
+	}
+
+	rest := u.base[offset+end+len("trailer"):]
+	start := bytes.Index(rest, []byte("<<"))
+	if start < 0 {
+		return nil, errors.New("PDF trailer has no dictionary")
+	}
+	dict, _, err := readValue(rest, start)
+	if err != nil {
+		return nil, err
+	}
+	return parseDict(dict)
+}
+
+// ids returns the numbers of all objects in the updated document in order.
+func (u *pdfUpdate) ids() []int {
+	var ids []int
+	for id := range u.offsets {
+		ids = append(ids, id)
+	}
+	for id := range u.objects {
+		if _, ok := u.offsets[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// object returns the body of object id, without its "obj" header.
+func (u *pdfUpdate) object(id int) ([]byte, error) {
+	if body, ok := u.objects[id]; ok {
+		return body, nil
+	}
+	offset, found := u.offsets[id]
+	if !found {
+		return nil, fmt.Errorf("object %d not found", id)
+	}
+	body := u.base[offset:]
+	header := fmt.Sprintf("%d 0 obj", id)
+	if !bytes.HasPrefix(body, []byte(header)) {
+		return nil, fmt.Errorf("object %d not found at its offset", id)
+	}
+	return body[len(header):], nil
+}
+
+// dict returns the dictionary of object id, or of its stream, as parsed entries.
+func (u *pdfUpdate) dict(id int) ([]dictEntry, error) {
+	entries, _, err := u.readDict(id)
+	return entries, err
+}
+
+// readDict returns the parsed dictionary of object id and what follows it.
+func (u *pdfUpdate) readDict(id int) ([]dictEntry, []byte, error) {
+	body, err := u.object(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	start := skipSpace(body, 0)
+	if !bytes.HasPrefix(body[start:], []byte("<<")) {
+		return nil, nil, fmt.Errorf("object %d is not a dictionary", id)
+	}
+	dict, end, err := readValue(body, start)
+	if err != nil {
+		return nil, nil, err
+	}
+	entries, err := parseDict(dict)
+	return entries, body[end:], err
+}
+
+// stream returns the dictionary and decoded data of stream object id. Only
+// unfiltered and FlateDecode streams with a direct /Length are supported.
+func (u *pdfUpdate) stream(id int) ([]dictEntry, []byte, error) {
+	entries, rest, err := u.readDict(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	length, err := strconv.Atoi(string(dictGet(entries, "Length")))
+	if err != nil {
+		return nil, nil, fmt.Errorf("object %d has no direct stream length", id)
+	}
+	if length < 0 {
+		return nil, nil, fmt.Errorf("object %d has a negative stream length", id)
+	}
+	start := skipSpace(rest, 0)
+	if !bytes.HasPrefix(rest[start:], []byte("stream")) {
+		return nil, nil, fmt.Errorf("object %d is not a stream", id)
+	}
+	start += len("stream")
+	if bytes.HasPrefix(rest[start:], []byte("\r")) {
+		start++
+	}
+	if bytes.HasPrefix(rest[start:], []byte("\n")) {
+		start++
+	}
+	if start+length > len(rest) {
+		return nil, nil, fmt.Errorf("object %d is truncated", id)
+	}
+	data := rest[start : start+length]
+
+	switch filter := string(dictGet(entries, "Filter")); filter {
+	case "":
+		return entries, data, nil
+	case "/FlateDecode":
+		zr, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, nil, fmt.Errorf("object %d: %w", id, err)
+		}
+		defer zr.Close()
+		decoded, err := io.ReadAll(zr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("object %d: %w", id, err)
+		}
+		return entries, decoded, nil
+	default:
+		return nil, nil, fmt.Errorf("object %d uses unsupported filter %s", id, filter)
+	}
+}
+
+// add appends a new object and returns its number.
+func (u *pdfUpdate) add(body []byte) int {
+	id := u.size
+	u.size++
+	u.objects[id] = body
+	return id
+}
+
+// set replaces object id.
+func (u *pdfUpdate) set(id int, body []byte) {
+	u.objects[id] = body
+}
+
+// root returns the number of the document catalog.
+func (u *pdfUpdate) root() (int, error) {
+	id, ok := refID(dictGet(u.trailer, "Root"))
+	if !ok {
+		return 0, errors.New("PDF trailer has no /Root")
+	}
+	return id, nil
+}
+
+// bytes returns the original document followed by the update.
+func (u *pdfUpdate) bytes() []byte {
+	var b bytes.Buffer
+	b.Write(u.base)
+	if !bytes.HasSuffix(u.base, []byte("\n")) {
+		b.WriteByte('\n')
+	}
+
+	var ids []int
+	for id := range u.objects {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	offsets := map[int]int{}
+	for _, id := range ids {
+		offsets[id] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n", id)
+		b.Write(u.objects[id])
+		b.WriteString("\nendobj\n")
+	}
+
+	xref := b.Len()
+	b.WriteString("xref\n")
+	for i := 0; i < len(ids); {
+		j := i + 1
+		for j < len(ids) && ids[j] == ids[j-1]+1 {
+			j++
+		}
+		fmt.Fprintf(&b, "%d %d\n", ids[i], j-i)
+		for _, id := range ids[i:j] {
+			fmt.Fprintf(&b, "%010d 00000 n \n", offsets[id])
+		}
+		i = j
+	}
+
+	trailer := dictSet(u.trailer, "Size", strconv.Itoa(u.size))
+	trailer = dictSet(trailer, "Prev", strconv.Itoa(u.prev))
+	b.WriteString("trailer\n")
+	b.Write(formatDict(trailer))
+	fmt.Fprintf(&b, "\nstartxref\n%d\n%%%%EOF\n", xref)
+	return b.Bytes()
+}
+
+// rewrite returns the updated document written from scratch: every object is
+// written once, followed by a single cross-reference table. Unlike bytes, it
+// leaves no replaced objects behind, which matters when large objects such as
+// page contents are replaced, but it breaks signatures of the original.
+func (u *pdfUpdate) rewrite() ([]byte, error) {
+	header := len(u.base)
+	for _, offset := range u.offsets {
+		header = min(header, offset)
+	}
+	var b bytes.Buffer
+	b.Write(u.base[:header])
+
+	ids := u.ids()
+	offsets := map[int]int{}
+	for _, id := range ids {
+		body, ok := u.objects[id]
+		if !ok {
+			var err error
+			if body, err = u.baseObject(id); err != nil {
+				return nil, err
+			}
+		}
+		offsets[id] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n", id)
+		b.Write(body)
+		b.WriteString("\nendobj\n")
+	}
+
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n", u.size)
+	for id := 0; id < u.size; id++ {
+		if offset, ok := offsets[id]; ok {
+			fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+		} else {
+			b.WriteString("0000000000 65535 f \n")
+		}
+	}
+
+	var trailer []dictEntry
+	for _, e := range dictSet(u.trailer, "Size", strconv.Itoa(u.size)) {
+		if e.key != "Prev" {
+			trailer = append(trailer, e)
+		}
+	}
+	b.WriteString("trailer\n")
+	b.Write(formatDict(trailer))
+	fmt.Fprintf(&b, "\nstartxref\n%d\n%%%%EOF\n", xref)
+	return b.Bytes(), nil
+}
+
+// baseObject returns the body of object id as written in the original
+// document, without its "obj" header and "endobj" keyword.
+func (u *pdfUpdate) baseObject(id int) ([]byte, error) {
+	offset, found := u.offsets[id]
+	if !found {
+		return nil, fmt.Errorf("object %d not found", id)
+	}
+	body, err := u.object(id)
+	if err != nil {
+		return nil, err
+	}
+	value, end, err := readValue(body, skipSpace(body, 0))
+	if err != nil {
+		return nil, fmt.Errorf("object %d: %w", id, err)
+	}
+	if rest := body[skipSpace(body, end):]; bytes.HasPrefix(rest, []byte("stream")) {
+		// Stream data may contain "endobj", so it is skipped by its length.
+		entries, err := parseDict(value)
+		if err != nil {
+			return nil, fmt.Errorf("object %d: %w", id, err)
+		}
+		length := dictGet(entries, "Length")
+		if lengthID, ok := refID(length); ok && lengthID != id {
+			lengthBody, err := u.object(lengthID)
+			if err != nil {
+				return nil, err
+			}
+			if length, _, err = readValue(lengthBody, skipSpace(lengthBody, 0)); err != nil {
+				return nil, fmt.Errorf("object %d: %w", lengthID, err)
+			}
+		}
+		n, err := strconv.Atoi(string(length))
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("object %d has an invalid stream length", id)
+		}
+		end = len(body) - len(rest) + len("stream")
+		if bytes.HasPrefix(body[end:], []byte("\r")) {
+			end++
+		}
+		if bytes.HasPrefix(body[end:], []byte("\n")) {
+			end++
+		}
+		end += min(n, len(body)-end)
+	}
+	i := bytes.Index(body[end:], []byte("endobj"))
+	if i < 0 {
+		return nil, fmt.Errorf("object %d at offset %d has no endobj", id, offset)
+	}
+	return bytes.TrimSpace(body[:end+i]), nil
+}
+
+// streamObject returns a stream object with the given extra dictionary entries.
+func streamObject(entries []dictEntry, data []byte) []byte {
+	entries = dictSet(entries, "Length", strconv.Itoa(len(data)))
+	var b bytes.Buffer
+	b.Write(formatDict(entries))
+	b.WriteString("\nstream\n")
+	b.Write(data)
+	b.WriteString("\nendstream")
+	return b.Bytes()
+}
+
+// flateStreamObject returns a FlateDecode-compressed stream object with the
+// given extra dictionary entries.
+func flateStreamObject(entries []dictEntry, data []byte) []byte {
+	var b bytes.Buffer
+	zw := zlib.NewWriter(&b)
+	_, _ = zw.Write(data)
+	_ = zw.Close()
+	return streamObject(dictSet(entries, "Filter", "/FlateDecode"), b.Bytes())
+}
+
+// ref returns an indirect reference to object id.
+func ref(id int) string {
+	return fmt.Sprintf("%d 0 R", id)
+}
+
+// refID returns the object number of an indirect reference such as "12 0 R".
+func refID(value []byte) (int, bool) {
+	fields := bytes.Fields(value)
+	if len(fields) != 3 || string(fields[2]) != "R" {
+		return 0, false
+	}
+	id, err := strconv.Atoi(string(fields[0]))
+	return id, err == nil
+}
+
+// dictGet returns the raw value of key, or nil when it is not set.
+func dictGet(entries []dictEntry, key string) []byte {
+	for _, e := range entries {
+		if e.key == key {
+			return e.value
+		}
+	}
+	return nil
+}
+
+// dictSet returns entries with key set to value, replacing an existing entry.
+func dictSet(entries []dictEntry, key, value string) []dictEntry {
+	out := make([]dictEntry, 0, len(entries)+1)
+	replaced := false
+	for _, e := range entries {
+		if e.key == key {
+			e.value = []byte(value)
+			replaced = true
+		}
+		out = append(out, e)
+	}
+	if !replaced {
+		out = append(out, dictEntry{key: key, value: []byte(value)})
+	}
+	return out
+}
+
+// formatDict writes entries as a dictionary, one entry per line.
+func formatDict(entries []dictEntry) []byte {
+	var b bytes.Buffer
+	b.WriteString("<<\n")
+	for _, e := range entries {
+		fmt.Fprintf(&b, "/%s %s\n", e.key, e.value)
+	}
+	b.WriteString(">>")
+	return b.Bytes()
+}
+
+// parseDict splits a dictionary such as "<< /Type /Page /Parent 2 0 R >>"
+// into its entries, keeping each value as written.
+func parseDict(dict []byte) ([]dictEntry, error) {
+	if !bytes.HasPrefix(dict, []byte("<<")) || !bytes.HasSuffix(dict, []byte(">>")) {
+		return nil, errors.New("not a dictionary")
+	}
+	body := dict[2 : len(dict)-2]
+	var entries []dictEntry
+	for i := skipSpace(body, 0); i < len(body); i = skipSpace(body, i) {
+		if body[i] != '/' {
+			return nil, fmt.Errorf("expected a name at %q", body[i:])
+		}
+		keyEnd := nameEnd(body, i+1)
+		key := string(body[i+1 : keyEnd])
+		start := skipSpace(body, keyEnd)
+		value, end, err := readValue(body, start)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, dictEntry{key: key, value: value})
+		i = end
+	}
+	return entries, nil
+}
+
+// readValue reads the PDF object starting at b[i] and returns it with the
+// index just past it. Numbers followed by "0 R" are read as references.
+func readValue(b []byte, i int) ([]byte, int, error) {
+	if i >= len(b) {
+		return nil, i, errors.New("unexpected end of object")
+	}
+	end := i
+	switch {
+	case bytes.HasPrefix(b[i:], []byte("<<")):
+		depth := 0
+		for end < len(b) {
+			switch {
+			case b[end] == '(':
+				end = stringEnd(b, end)
+				continue
+			case bytes.HasPrefix(b[end:], []byte("<<")):
+				depth++
+				end += 2
+				continue
+			case bytes.HasPrefix(b[end:], []byte(">>")):
+				depth--
+				end += 2
+				if depth == 0 {
+					return b[i:end], end, nil
+				}
+				continue
+			}
+			end++
+		}
+		return nil, end, errors.New("unterminated dictionary")
+	case b[i] == '[':
+		depth := 0
+		for end < len(b) {
+			switch b[end] {
+			case '(':
+				end = stringEnd(b, end)
+				continue
+			case '[':
+				depth++
+			case ']':
+				depth--
+				if depth == 0 {
+					return b[i : end+1], end + 1, nil
+				}
+			}
+			end++
+		}
+		return nil, end, errors.New("unterminated array")
+	case b[i] == '(':
+		end = stringEnd(b, i)
+		return b[i:end], end, nil
+	case b[i] == '<':
+		end = bytes.IndexByte(b[i:], '>')
+		if end < 0 {
+			return nil, len(b), errors.New("unterminated hex string")
+		}
+		return b[i : i+end+1], i + end + 1, nil
+	case b[i] == '/':
+		end = nameEnd(b, i+1)
+		return b[i:end], end, nil
+	}
+
+	end = nameEnd(b, i)
+	if end == i {
+		return nil, i, fmt.Errorf("unexpected %q", b[i])
+	}
+	// An object number may be followed by its generation and R.
+	if isDigits(b[i:end]) {
+		j := skipSpace(b, end)
+		k := nameEnd(b, j)
+		l := skipSpace(b, k)
+		if k > j && isDigits(b[j:k]) && l < len(b) && b[l] == 'R' && nameEnd(b, l) == l+1 {
+			return b[i : l+1], l + 1, nil
+		}
+	}
+	return b[i:end], end, nil
+}
+
+// isDigits reports whether b is a non-empty run of decimal digits.
+func isDigits(b []byte) bool {
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return len(b) > 0
+}
+
+// stringEnd returns the index just past the literal string starting at b[i].
+func stringEnd(b []byte, i int) int {
+	depth := 0
+	for j := i; j < len(b); j++ {
+		switch b[j] {
+		case '\\':
+			j++
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return j + 1
+			}
+		}
+	}
+	return len(b)
+}
+
+// nameEnd returns the index of the first delimiter or space at or after i.
+func nameEnd(b []byte, i int) int {
+	for i < len(b) && !bytes.ContainsRune([]byte(" \t\r\n\f/[]<>()"), rune(b[i])) {
+		i++
+	}
+	return i
+}
+
+// skipSpace returns the index of the first non-space byte at or after i.
+func skipSpace(b []byte, i int) int {
+	for i < len(b) && bytes.ContainsRune([]byte(" \t\r\n\f\x00"), rune(b[i])) {
+		i++
+	}
+	return i
+}
+
+// pages returns the object numbers of the document's pages in order.
+func (u *pdfUpdate) pages() ([]int, error) {
+	rootID, err := u.root()
+	if err != nil {
+		return nil, err
+	}
+	catalog, err := u.dict(rootID)
+	if err != nil {
+		return nil, err
+	}
+	id, ok := refID(dictGet(catalog, "Pages"))
+	if !ok {
+		return nil, errors.New("PDF catalog has no /Pages")
+	}
+	return u.pageTree(id, map[int]bool{})
+}
+
+// pageTree returns the pages below the page tree node id in order. Nodes
+// already in seen are refused, so a cyclic /Kids tree is an error.
+func (u *pdfUpdate) pageTree(id int, seen map[int]bool) ([]int, error) {
+	if seen[id] {
+		return nil, fmt.Errorf("page tree node %d is referenced more than once", id)
+	}
+	seen[id] = true
+	node, err := u.dict(id)
+	if err != nil {
+		return nil, err
+	}
+	if string(dictGet(node, "Type")) == "/Page" {
+		return []int{id}, nil
+	}
+	var pages []int
+	for _, kid := range arrayRefs(dictGet(node, "Kids")) {
+		kidPages, err := u.pageTree(kid, seen)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, kidPages...)
+	}
+	return pages, nil
+}
+
+// pageTop returns the top edge of the /MediaBox of page pageID, which may be
+// inherited from its page tree ancestors. The renderer's y coordinates are
+// measured down from this edge.
+func (u *pdfUpdate) pageTop(pageID int) (float64, error) {
+	seen := map[int]bool{}
+	for id := pageID; !seen[id]; {
+		seen[id] = true
+		node, err := u.dict(id)
+		if err != nil {
+			return 0, err
+		}
+		if box := dictGet(node, "MediaBox"); box != nil {
+			fields := bytes.Fields(bytes.Trim(box, "[]"))
+			if len(fields) != 4 {
+				return 0, fmt.Errorf("page %d has an invalid /MediaBox %s", pageID, box)
+			}
+			top, err := strconv.ParseFloat(string(fields[3]), 64)
+			if err != nil {
+				return 0, fmt.Errorf("page %d has an invalid /MediaBox %s", pageID, box)
+			}
+			return top, nil
+		}
+		parent, ok := refID(dictGet(node, "Parent"))
+		if !ok {
+			break
+		}
+		id = parent
+	}
+	return 0, fmt.Errorf("page %d has no /MediaBox", pageID)
+}
+
+// addAnnotation appends annotation annotID to the /Annots of page pageID.
+func (u *pdfUpdate) addAnnotation(pageID, annotID int) error {
+	page, err := u.dict(pageID)
+	if err != nil {
+		return err
+	}
+	annots := dictGet(page, "Annots")
+	if annots != nil && !bytes.HasPrefix(annots, []byte("[")) {
+		return errors.New("indirect /Annots arrays are not supported")
+	}
+	u.set(pageID, formatDict(dictSet(page, "Annots", appendRefs(annots, annotID))))
+	return nil
+}
+
+// addFormFields adds fields to the document's interactive form (AcroForm),
+// creating the form when needed, and lets set adjust its other entries.
+func (u *pdfUpdate) addFormFields(fields []int, set func([]dictEntry) []dictEntry) error {
+	rootID, err := u.root()
+	if err != nil {
+		return err
+	}
+	catalog, err := u.dict(rootID)
+	if err != nil {
+		return err
+	}
+
+	var form []dictEntry
+	value := dictGet(catalog, "AcroForm")
+	formID, indirect := refID(value)
+	switch {
+	case indirect:
+		form, err = u.dict(formID)
+	case value != nil:
+		form, err = parseDict(value)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(fields) > 0 || dictGet(form, "Fields") == nil {
+		form = dictSet(form, "Fields", appendRefs(dictGet(form, "Fields"), fields...))
+	}
+	if set != nil {
+		form = set(form)
+	}
+	if indirect {
+		u.set(formID, formatDict(form))
+	} else {
+		u.set(rootID, formatDict(dictSet(catalog, "AcroForm", string(formatDict(form)))))
+	}
+	return nil
+}
+
+// arrayRefs returns the object numbers referenced in an array such as
+// "[3 0 R 7 0 R]".
+func arrayRefs(array []byte) []int {
+	var ids []int
+	fields := bytes.Fields(bytes.Trim(array, "[]"))
+	for i := 0; i+2 < len(fields); i++ {
+		if string(fields[i+2]) != "R" {
+			continue
+		}
+		if id, err := strconv.Atoi(string(fields[i])); err == nil {
+			ids = append(ids, id)
+			i += 2
+		}
+	}
+	return ids
+}
+
+// appendRefs returns array with references to ids appended; a nil array is
+// treated as empty.
+func appendRefs(array []byte, ids ...int) string {
+	var b strings.Builder
+	b.WriteString("[")
+	if inner := strings.TrimSpace(string(bytes.Trim(array, "[]"))); inner != "" {
+		b.WriteString(inner)
+		b.WriteString(" ")
+	}
+	for i, id := range ids {
+		if i > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString(ref(id))
+	}
+	b.WriteString("]")
+	return b.String()
+}
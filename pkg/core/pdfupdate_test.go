@@ -0,0 +1,248 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// minimalPDF returns a one-object PDF with a classic cross-reference table.
+func minimalPDF() []byte {
+	obj := "1 0 obj\n<< /Type /Catalog /Pages 2 0 R /Names << /Dests (a(b)c) >> >>\nendobj\n"
+	head := "%PDF-1.7\n"
+	xref := len(head) + len(obj)
+	return []byte(head + obj + "xref\n0 2\n0000000000 65535 f \n0000000009 00000 n \n" +
+		"trailer\n<< /Size 2 /Root 1 0 R >>\nstartxref\n" + strconv.Itoa(xref) + "\n%%EOF\n")
+}
+
+// buildPDF returns a PDF of the given objects, numbered from 1, with a single
+// cross-reference table. The xref subsection header and the trailer
+// dictionary can be replaced; "XREF" in the trailer is replaced by the offset
+// of the table.
+func buildPDF(objects []string, subsection, trailer string) []byte {
+	var b strings.Builder
+	b.WriteString("%PDF-1.7\n")
+	var offsets []int
+	for i, obj := range objects {
+		offsets = append(offsets, b.Len())
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := b.Len()
+	if subsection == "" {
+		subsection = fmt.Sprintf("0 %d", len(objects)+1)
+	}
+	b.WriteString("xref\n" + subsection + "\n0000000000 65535 f \n")
+	for _, off := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	b.WriteString("trailer\n" + strings.ReplaceAll(trailer, "XREF", strconv.Itoa(xref)))
+	fmt.Fprintf(&b, "\nstartxref\n%d\n%%%%EOF\n", xref)
+	return []byte(b.String())
+}
+
+func TestParseDict(t *testing.T) {
+	entries, err := parseDict([]byte("<</Type /Annot /Rect [0 0 10 10] /P 3 0 R/A <</URI (x) >>/T (a\\)b) /H <FEFF> /N 12>>"))
+	assert.NoError(t, err)
+	assert.Equal(t, "/Annot", string(dictGet(entries, "Type")))
+	assert.Equal(t, "[0 0 10 10]", string(dictGet(entries, "Rect")))
+	assert.Equal(t, "3 0 R", string(dictGet(entries, "P")))
+	assert.Equal(t, "<</URI (x) >>", string(dictGet(entries, "A")))
+	assert.Equal(t, `(a\)b)`, string(dictGet(entries, "T")))
+	assert.Equal(t, "<FEFF>", string(dictGet(entries, "H")))
+	assert.Equal(t, "12", string(dictGet(entries, "N")))
+
+	id, ok := refID(dictGet(entries, "P"))
+	assert.True(t, ok)
+	assert.Equal(t, 3, id)
+}
+
+func TestPDFUpdate_Chain(t *testing.T) {
+	u, err := newPDFUpdate(minimalPDF())
+	assert.NoError(t, err)
+	catalog, err := u.dict(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "<< /Dests (a(b)c) >>", string(dictGet(catalog, "Names")))
+
+	u.set(1, formatDict(dictSet(catalog, "Lang", "(en)")))
+	added := u.add([]byte("<< /Type /Test >>"))
+	assert.Equal(t, 2, added)
+	first := u.bytes()
+
+	u, err = newPDFUpdate(first)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, u.ids())
+	catalog, err = u.dict(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "(en)", string(dictGet(catalog, "Lang")), "the replaced object is read from the update")
+	assert.Equal(t, "3", string(dictGet(u.trailer, "Size")))
+
+	u.add([]byte("<< /Type /Second >>"))
+	second, err := newPDFUpdate(u.bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, second.ids(), "objects of every update are found through /Prev")
+	dict, err := second.dict(2)
+	assert.NoError(t, err)
+	assert.Equal(t, "/Test", string(dictGet(dict, "Type")))
+}
+
+// pageContents returns the decoded content stream of every page of pdf.
+func pageContents(t *testing.T, pdf []byte) []string {
+	u, err := newPDFUpdate(pdf)
+	require.NoError(t, err)
+	pages, err := u.pages()
+	require.NoError(t, err)
+	var contents []string
+	for _, id := range pages {
+		page, err := u.dict(id)
+		require.NoError(t, err)
+		contentID, ok := refID(dictGet(page, "Contents"))
+		require.True(t, ok)
+		_, data, err := u.stream(contentID)
+		require.NoError(t, err)
+		contents = append(contents, string(data))
+	}
+	return contents
+}
+
+// pageText returns the text drawn on every page of pdf, decoded through the
+// ToUnicode maps of the fonts gopdf embeds.
+func pageText(t *testing.T, pdf []byte) []string {
+	var texts []string
+	for _, runs := range pageRuns(t, pdf) {
+		var text strings.Builder
+		for _, run := range runs {
+			text.WriteString(run.text)
+		}
+		texts = append(texts, text.String())
+	}
+	return texts
+}
+
+// textRun is one TJ operation of a content stream: its decoded text, the
+// font resource it is drawn with and the position of its text object.
+type textRun struct {
+	font string
+	x, y float64
+	text string
+}
+
+// pageRuns returns the text runs drawn on every page of pdf.
+func pageRuns(t *testing.T, pdf []byte) [][]textRun {
+	u, err := newPDFUpdate(pdf)
+	require.NoError(t, err)
+	pages, err := u.pages()
+	require.NoError(t, err)
+	contents := pageContents(t, pdf)
+
+	ops := regexp.MustCompile(`([\d.]+) ([\d.]+) TD|/(\w+) [\d.]+ Tf|\[<([0-9A-F]*)>\] TJ`)
+	var runs [][]textRun
+	for i, id := range pages {
+		page, err := u.dict(id)
+		require.NoError(t, err)
+		resources := dictGet(page, "Resources")
+		if resourcesID, ok := refID(resources); ok {
+			resources, err = u.object(resourcesID)
+			require.NoError(t, err)
+			resources, _, err = readValue(resources, skipSpace(resources, 0))
+			require.NoError(t, err)
+		}
+		entries, err := parseDict(resources)
+		require.NoError(t, err)
+		fonts, err := parseDict(dictGet(entries, "Font"))
+		require.NoError(t, err)
+
+		var pageRuns []textRun
+		var current textRun
+		var glyphs map[int]rune
+		for _, m := range ops.FindAllStringSubmatch(contents[i], -1) {
+			switch {
+			case m[1] != "":
+				current.x, _ = strconv.ParseFloat(m[1], 64)
+				current.y, _ = strconv.ParseFloat(m[2], 64)
+			case m[3] != "":
+				fontID, ok := refID(dictGet(fonts, m[3]))
+				require.True(t, ok, "font %s", m[3])
+				glyphs = toUnicode(t, u, fontID)
+				current.font = m[3]
+			default:
+				var text strings.Builder
+				for k := 0; k+4 <= len(m[4]); k += 4 {
+					gid, _ := strconv.ParseInt(m[4][k:k+4], 16, 32)
+					text.WriteRune(glyphs[int(gid)])
+				}
+				run := current
+				run.text = text.String()
+				pageRuns = append(pageRuns, run)
+			}
+		}
+		runs = append(runs, pageRuns)
+	}
+	return runs
+}
+
+// toUnicode returns the characters of the glyphs of font fontID.
+func toUnicode(t *testing.T, u *pdfUpdate, fontID int) map[int]rune {
+	font, err := u.dict(fontID)
+	require.NoError(t, err)
+	cmapID, ok := refID(dictGet(font, "ToUnicode"))
+	require.True(t, ok)
+	_, cmap, err := u.stream(cmapID)
+	require.NoError(t, err)
+
+	glyphs := map[int]rune{}
+	ranges := regexp.MustCompile(`<([0-9A-F]{4})><([0-9A-F]{4})><([0-9A-F]{4})>`)
+	for _, m := range ranges.FindAllStringSubmatch(string(cmap), -1) {
+		first, _ := strconv.ParseInt(m[1], 16, 32)
+		last, _ := strconv.ParseInt(m[2], 16, 32)
+		r, _ := strconv.ParseInt(m[3], 16, 32)
+		for gid := first; gid <= last; gid++ {
+			glyphs[int(gid)] = rune(r + gid - first)
+		}
+	}
+	return glyphs
+}
+
+func TestPDFUpdate_StreamLength(t *testing.T) {
+	for name, length := range map[string]string{"negative": "-5", "too long": "500"} {
+		t.Run(name, func(t *testing.T) {
+			pdf := buildPDF([]string{
+				"<< /Type /Catalog /Pages 2 0 R >>",
+				"<< /Type /Pages /Kids [] /Count 0 >>",
+				"<< /Length " + length + " >>\nstream\nq Q\nendstream",
+			}, "", "<< /Size 4 /Root 1 0 R >>")
+			u, err := newPDFUpdate(pdf)
+			require.NoError(t, err)
+			_, _, err = u.stream(3)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestPDFUpdate_PageTop(t *testing.T) {
+	pdf := buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R 5 0 R] /Count 3 /MediaBox [0 0 612 792] >>",
+		"<< /Type /Page /Parent 2 0 R >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [ 0 0 595.28 841.89 ] >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595.28] >>",
+		"<< /Type /Page >>",
+	}, "", "<< /Size 7 /Root 1 0 R >>")
+	u, err := newPDFUpdate(pdf)
+	require.NoError(t, err)
+
+	top, err := u.pageTop(3)
+	assert.NoError(t, err)
+	assert.Equal(t, 792.0, top, "inherited from the page tree")
+	top, err = u.pageTop(4)
+	assert.NoError(t, err)
+	assert.Equal(t, 841.89, top)
+	_, err = u.pageTop(5)
+	assert.ErrorContains(t, err, "invalid /MediaBox")
+	_, err = u.pageTop(6)
+	assert.ErrorContains(t, err, "no /MediaBox")
+}
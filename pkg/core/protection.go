@@ -0,0 +1,62 @@
+// File: renderer/protection.go
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/signintech/gopdf"
+)
+
+// maxPasswordLength is the longest password the PDF standard security handler
+// uses; longer passwords would be silently truncated.
+const maxPasswordLength = 32
+
+// ParsePermissions parses a comma-separated list of "print", "copy", and
+// "modify" (or "none"/empty for no permissions).
+func ParsePermissions(s string) (Permission, error) {
+	var perms Permission
+	for _, name := range strings.Split(s, ",") {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "", "none":
+		case "print":
+			perms |= PermissionPrint
+		case "copy":
+			perms |= PermissionCopy
+		case "modify":
+			perms |= PermissionModify
+		default:
+			return 0, fmt.Errorf("unknown permission %q: use print, copy, or modify", name)
+		}
+	}
+	return perms, nil
+}
+
+// protect replaces the document with one encrypted according to r.Protection.
+// gopdf encrypts objects as they are created, so this must happen before
+// anything is drawn; RenderHTMLLikeToBuffer calls it first.
+func (r *Renderer) protect() error {
+	p := r.Protection
+	if len(p.UserPassword) > maxPasswordLength || len(p.OwnerPassword) > maxPasswordLength {
+		return fmt.Errorf("PDF passwords must be at most %d bytes", maxPasswordLength)
+	}
+	if p.UserPassword != "" && p.UserPassword == p.OwnerPassword {
+		return errors.New("PDF user and owner passwords must differ")
+	}
+
+	pdf, err := newPDFWithConfig(gopdf.Config{
+		PageSize: *gopdf.PageSizeA4,
+		Protection: gopdf.PDFProtectionConfig{
+			UseProtection: true,
+			Permissions:   int(p.Permissions),
+			UserPass:      []byte(p.UserPassword),
+			OwnerPass:     []byte(p.OwnerPassword),
+		},
+	}, r.FontSize.P)
+	if err != nil {
+		return err
+	}
+	r.pdf = pdf
+	return nil
+}
@@ -0,0 +1,53 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderHTMLLikeToBuffer_Protection(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	r.Protection = &Protection{UserPassword: "open", OwnerPassword: "admin", Permissions: PermissionPrint | PermissionCopy}
+
+	buf, err := r.RenderHTMLLikeToBuffer(`<p>Salary: 1000</p>`)
+	assert.NoError(t, err)
+
+	pdf := buf.String()
+	assert.Contains(t, pdf, "/Filter /Standard")
+	assert.Contains(t, pdf, "/Encrypt ")
+	// 192|4|16 with the unused high bits set, as a signed 32-bit value.
+	assert.Contains(t, pdf, "/P -44\n")
+}
+
+func TestRenderHTMLLikeToBuffer_ProtectionErrors(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	r.Protection = &Protection{UserPassword: strings.Repeat("x", 33)}
+	_, err := r.RenderHTMLLikeToBuffer(`<p>Body</p>`)
+	assert.ErrorContains(t, err, "at most 32 bytes")
+
+	r, _ = NewRenderer(defaultFontSizes(), true)
+	r.Protection = &Protection{UserPassword: "same", OwnerPassword: "same"}
+	_, err = r.RenderHTMLLikeToBuffer(`<p>Body</p>`)
+	assert.ErrorContains(t, err, "must differ")
+}
+
+func TestParsePermissions(t *testing.T) {
+	perms, err := ParsePermissions("print, Copy,modify")
+	assert.NoError(t, err)
+	assert.Equal(t, PermissionPrint|PermissionCopy|PermissionModify, perms)
+
+	perms, err = ParsePermissions("none")
+	assert.NoError(t, err)
+	assert.Equal(t, Permission(0), perms)
+
+	_, err = ParsePermissions("print,annotate")
+	assert.ErrorContains(t, err, `unknown permission "annotate"`)
+}
@@ -10,17 +10,101 @@ import (
 // It encapsulates the gopdf instance, font settings, current layout position, and additional options
 // such as header/footer images and timestamp rendering.
 type Renderer struct {
-	pdf               *gopdf.GoPdf // Internal PDF instance from gopdf.
-	y                 float64      // Current vertical position on the page.
-	pageWidth         float64      // Width of the current page (default A4).
-	footerText        string       // Footer text to be rendered on each page.
-	pageNumber        int          // Current page number.
-	showPageNumber    bool         // Whether to render the page number in the footer.
-	backgroundImg     string       // Base64-encoded background image path.
-	headerImg         string       // Base64-encoded header image path.
-	footerImg         string       // Base64-encoded footer image path.
-	FontSize          FontSizes    // Font size configuration for the document.
-	TopRightTimestamp string       // Optional timestamp text to be shown at the top-right of each page.
+	pdf               *gopdf.GoPdf     // Internal PDF instance from gopdf.
+	y                 float64          // Current vertical position on the page.
+	pageTop           float64          // Vertical position where content starts on the current page.
+	left              float64          // Left edge of the current content column.
+	width             float64          // Width of the current content column (495pt across an A4 page).
+	pageWidth         float64          // Width of the current page (default A4).
+	footerText        string           // Footer text to be rendered on each page.
+	pageNumber        int              // Current page number.
+	showPageNumber    bool             // Whether to render the page number in the footer.
+	backgroundImg     string           // Base64-encoded background image path.
+	headerImg         string           // Base64-encoded header image path.
+	footerImg         string           // Base64-encoded footer image path.
+	FontSize          FontSizes        // Font size configuration for the document.
+	TopRightTimestamp string           // Optional timestamp text to be shown at the top-right of each page.
+	TableStyle        TableStyle       // Styling applied to rendered tables.
+	LinkStyle         LinkStyle        // Styling applied to hyperlink text.
+	PageNumbers       PageNumberFormat // Template, style, and placement of page numbers.
+	Pagination        Pagination       // Minimum orphan and widow lines of paragraphs.
+
+	// TableOfContents enables a generated table of contents when non-nil. It is
+	// drawn at the <toc> element if the document has one, otherwise on its own
+	// page(s) before the content.
+	TableOfContents *TableOfContents
+
+	// Bookmarks enables a PDF outline (bookmark tree) built from h1–h3 headings.
+	Bookmarks bool
+
+	// Columns lays the whole document out in this many columns when greater
+	// than one, separated by ColumnGap (1em when zero).
+	Columns   int
+	ColumnGap float64
+
+	// BackgroundImageOptions, HeaderImageOptions, and FooterImageOptions place
+	// the background, header, and footer images on each page.
+	BackgroundImageOptions PageImageOptions
+	HeaderImageOptions     PageImageOptions
+	FooterImageOptions     PageImageOptions
+
+	// Watermark is drawn across every page when non-nil.
+	Watermark *Watermark
+
+	// TemplatePDF draws a page of an existing PDF beneath every page when non-nil.
+	TemplatePDF *TemplatePDF
+
+	// Attachments are embedded in the PDF as files.
+	Attachments []Attachment
+
+	// HeaderTemplate and FooterTemplate are HTML fragments drawn as running
+	// headers and footers on every page. <header>/<footer> blocks in the
+	// document take precedence over them.
+	HeaderTemplate string
+	FooterTemplate string
+
+	// Protection encrypts the PDF with passwords and permissions when non-nil.
+	Protection *Protection
+
+	// PDFA makes the output conform to PDF/A-2b for long-term archiving.
+	PDFA bool
+
+	// Tagged adds a structure tree to the PDF for assistive technology, built
+	// from the document's headings, paragraphs, tables, lists, and images.
+	Tagged bool
+
+	// Metadata is written to the PDF's document properties.
+	Metadata Metadata
+
+	// Decorators draw on every page independent of its content; see
+	// PageDecorator. NewRenderer installs DefaultPageDecorators.
+	Decorators []PageDecorator
+
+	anchorIDs        map[string]bool        // Element ids present in the document, used as internal link targets.
+	pendingAnchors   []string               // Element ids waiting to be placed at the next content position.
+	anchorPages      map[string]int         // Page number on which each placed anchor ended up.
+	tocEntries       []tocEntry             // Headings listed in the table of contents.
+	tocRendered      bool                   // Whether the table of contents has been drawn.
+	outlineRoots     []*gopdf.OutlineNode   // Top-level bookmarks, with nested children.
+	outlineStack     []outlineLevel         // Currently open bookmarks, used to nest the next heading.
+	header           runningBlock           // Running header variants.
+	footer           runningBlock           // Running footer variants.
+	title            string                 // Text of the document's <title>.
+	inRunningBlock   bool                   // Whether a running header or footer is being drawn.
+	measuring        bool                   // Whether content is being laid out on the scratch PDF to measure it.
+	measureLimit     float64                // Height at which the measurement in progress stops, or zero.
+	scratchFonts     []*gopdf.FontContainer // Parsed fonts of the scratch PDFs, one container per style.
+	columns          *columnLayout          // Multi-column section being laid out, if any.
+	inRow            bool                   // Whether a column of a row container is being laid out.
+	signatureFields  []signatureField       // Signature boxes reserved by <signature> elements.
+	formFields       []formField            // Form fields reserved by <input>, <select>, and <textarea> elements.
+	templateID       int                    // Imported template of TemplatePDF, or -1 when it could not be imported.
+	templateImported bool                   // Whether TemplatePDF has been imported.
+	structElems      []structElem           // Structure elements of a tagged PDF, in document order.
+	openTags         []int                  // Structure elements currently open, innermost last.
+	linkTags         []linkTag              // Link elements of link annotations, in the order they were added.
+	markers          int                    // Structure markers written into content streams.
+	lists            []listLevel            // Lists being laid out, innermost last.
 }
 
 // NewRenderer initializes a new PDF renderer with the specified font sizes and
@@ -29,42 +113,37 @@ type Renderer struct {
 //
 // Returns a Renderer instance ready to write PDF content.
 func NewRenderer(fontSizes FontSizes, showPageNumber bool) (*Renderer, error) {
-	pdf := &gopdf.GoPdf{}
-	pdf.Start(gopdf.Config{PageSize: *gopdf.PageSizeA4})
-	pdf.AddPage()
-
-	fonts, err := findFontPaths()
+	pdf, err := newPDF(fontSizes.P)
 	if err != nil {
 		return nil, err
 	}
 
-	// Load fonts with styles
-	if err := pdf.AddTTFFontWithOption("Arial", fonts.Regular, gopdf.TtfOption{Style: gopdf.Regular}); err != nil {
-		return nil, fmt.Errorf("regular font: %w", err)
-	}
-	if err := pdf.AddTTFFontWithOption("Arial", fonts.Bold, gopdf.TtfOption{Style: gopdf.Bold}); err != nil {
-		return nil, fmt.Errorf("bold font: %w", err)
-	}
-	if err := pdf.AddTTFFontWithOption("Arial", fonts.Italic, gopdf.TtfOption{Style: gopdf.Italic}); err != nil {
-		return nil, fmt.Errorf("italic font: %w", err)
-	}
-	if err := pdf.SetFont("Arial", "", fontSizes.P); err != nil {
-		return nil, err
-	}
-
 	return &Renderer{
-		pdf:            pdf,
-		y:              50,
-		pageNumber:     1,
-		showPageNumber: showPageNumber,
-		FontSize:       fontSizes,
+		pdf:                    pdf,
+		y:                      50,
+		pageTop:                50,
+		left:                   50,
+		width:                  495,
+		pageNumber:             1,
+		showPageNumber:         showPageNumber,
+		FontSize:               fontSizes,
+		TableStyle:             DefaultTableStyle(),
+		LinkStyle:              DefaultLinkStyle(),
+		PageNumbers:            DefaultPageNumberFormat(),
+		Pagination:             DefaultPagination(),
+		BackgroundImageOptions: DefaultBackgroundImageOptions(),
+		HeaderImageOptions:     DefaultHeaderImageOptions(),
+		FooterImageOptions:     DefaultFooterImageOptions(),
+		Decorators:             DefaultPageDecorators(),
 	}, nil
 }
 
 // NewRendererWithBase64Images creates a new Renderer and overlays background,
 // header, and footer images from base64 strings.
 //
-// The images are saved to temporary files and rendered immediately.
+// The images are saved to temporary files and drawn on every page, placed
+// according to the renderer's image options; the first page is decorated when
+// rendering starts, so options set after construction apply to it too.
 // This function is useful when generating branded or templated reports
 // with header/footer banners.
 //
@@ -89,17 +168,38 @@ func NewRendererWithBase64Images(bgBase64, headerBase64, footerBase64 string, fo
 	r.backgroundImg = bgPath
 	r.headerImg = headerPath
 	r.footerImg = footerPath
+	return r, nil
+}
+
+// newPDF starts an A4 document with one page and the Arial regular, bold, and
+// italic faces loaded, with the regular face selected at the given size.
+func newPDF(fontSize float64) (*gopdf.GoPdf, error) {
+	return newPDFWithConfig(gopdf.Config{PageSize: *gopdf.PageSizeA4}, fontSize)
+}
+
+// newPDFWithConfig is like newPDF but starts the document with the given config.
+func newPDFWithConfig(config gopdf.Config, fontSize float64) (*gopdf.GoPdf, error) {
+	pdf := &gopdf.GoPdf{}
+	pdf.Start(config)
+	pdf.AddPage()
+
+	fonts, err := findFontPaths()
+	if err != nil {
+		return nil, err
+	}
 
-	// Draw background and header/footer images on the first page
-	if bgPath != "" {
-		_ = r.pdf.Image(bgPath, 0, 0, &gopdf.Rect{W: 595.28, H: 841.89})
+	// Load fonts with styles
+	if err := pdf.AddTTFFontWithOption("Arial", fonts.Regular, gopdf.TtfOption{Style: gopdf.Regular}); err != nil {
+		return nil, fmt.Errorf("regular font: %w", err)
 	}
-	if headerPath != "" {
-		_ = r.pdf.Image(headerPath, 50, 20, &gopdf.Rect{W: 495.0, H: 40.0})
-		r.y += 50
+	if err := pdf.AddTTFFontWithOption("Arial", fonts.Bold, gopdf.TtfOption{Style: gopdf.Bold}); err != nil {
+		return nil, fmt.Errorf("bold font: %w", err)
+	}
+	if err := pdf.AddTTFFontWithOption("Arial", fonts.Italic, gopdf.TtfOption{Style: gopdf.Italic}); err != nil {
+		return nil, fmt.Errorf("italic font: %w", err)
 	}
-	if footerPath != "" {
-		_ = r.pdf.Image(footerPath, 50, 800, &gopdf.Rect{W: 495.0, H: 30.0})
+	if err := pdf.SetFont("Arial", "", fontSize); err != nil {
+		return nil, err
 	}
-	return r, nil
+	return pdf, nil
 }
@@ -0,0 +1,118 @@
+// File: renderer/row.go
+package core
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// isRow reports whether n is a horizontal row container: an element with the
+// "row" class or styled display: flex.
+func isRow(n *html.Node) bool {
+	if hasClass(n, "row") {
+		return true
+	}
+	display, _ := styleProperty(n, "display")
+	return strings.EqualFold(display, "flex")
+}
+
+// rowGap returns the gutter between the columns of a row, from its gap or
+// column-gap style. It defaults to zero, as in CSS.
+func rowGap(n *html.Node) float64 {
+	for _, name := range []string{"column-gap", "gap"} {
+		if val, ok := styleProperty(n, name); ok {
+			if v, ok := parseLength(val); ok {
+				return v
+			}
+		}
+	}
+	return 0
+}
+
+// rowWidths returns the width of each column of a row that is width points
+// wide. Columns use their width style (a percentage of the row or a length);
+// the others share the remaining space equally. Columns that do not fit
+// together are shrunk proportionally.
+func rowWidths(cols []*html.Node, width, gap float64) []float64 {
+	available := width - gap*float64(len(cols)-1)
+	widths := make([]float64, len(cols))
+	used, auto := 0.0, 0
+	for i, col := range cols {
+		val, ok := styleProperty(col, "width")
+		if ok {
+			if w, ok := parseSize(val, width); ok {
+				widths[i] = w
+				used += w
+				continue
+			}
+		}
+		widths[i] = -1
+		auto++
+	}
+
+	share := 0.0
+	if auto > 0 {
+		share = max(available-used, 0) / float64(auto)
+	}
+	total := 0.0
+	for i := range widths {
+		if widths[i] < 0 {
+			widths[i] = share
+		}
+		total += widths[i]
+	}
+	if total > available && total > 0 {
+		for i := range widths {
+			widths[i] *= available / total
+		}
+	}
+	return widths
+}
+
+// renderRow lays out the element children of a row side by side, each in its
+// own vertical flow starting at the top of the row; the content after the row
+// continues below its tallest column. A row that does not fit on the rest of
+// the page is moved to the next one as a whole. Nothing inside a row breaks
+// pages, so a row taller than a page is stacked instead: its columns are laid
+// out one below the other across the full width, breaking pages as needed.
+func (r *Renderer) renderRow(n *html.Node) {
+	var cols []*html.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode {
+			cols = append(cols, c)
+		}
+	}
+	if len(cols) == 0 {
+		return
+	}
+
+	if r.paginated() {
+		height := r.measureHeight(n, r.pageHeight())
+		if height > r.bottom()-r.top() {
+			for _, col := range cols {
+				r.walk(col)
+			}
+			return
+		}
+		if r.y+height > r.bottom() {
+			r.breakPage()
+		}
+	}
+	r.checkPageBreak(0)
+
+	gap := rowGap(n)
+	widths := rowWidths(cols, r.width, gap)
+
+	inRow, left, width := r.inRow, r.left, r.width
+	top, bottom := r.y, r.y
+	r.inRow = true
+	x := left
+	for i, col := range cols {
+		r.left, r.width, r.y = x, widths[i], top
+		r.walk(col)
+		bottom = max(bottom, r.y)
+		x += widths[i] + gap
+	}
+	r.inRow, r.left, r.width, r.y = inRow, left, width, bottom
+}
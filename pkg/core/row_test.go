@@ -0,0 +1,93 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/html"
+)
+
+func TestIsRow(t *testing.T) {
+	doc, _ := html.Parse(strings.NewReader(`
+		<div class="dashboard row"></div>
+		<div style="display: flex"></div>
+		<div class="rows"></div>`))
+	divs := findElements(doc, "div")
+
+	assert.True(t, isRow(divs[0]))
+	assert.True(t, isRow(divs[1]))
+	assert.False(t, isRow(divs[2]))
+}
+
+func TestRowWidths(t *testing.T) {
+	doc, _ := html.Parse(strings.NewReader(`<div class="row">
+		<div class="col" style="width: 50%"></div>
+		<div class="col"></div>
+		<div class="col" style="width: 100px"></div></div>`))
+	cols := findElements(findNode(doc, "div"), "div")[1:]
+
+	assert.Equal(t, []float64{200, 100, 100}, rowWidths(cols, 400, 0))
+	assert.Equal(t, []float64{200, 80, 100}, rowWidths(cols, 400, 10), "auto columns get what the gaps leave")
+
+	wide, _ := html.Parse(strings.NewReader(`<div style="width: 80%"></div><div style="width: 80%"></div>`))
+	assert.Equal(t, []float64{200, 200}, rowWidths(findElements(wide, "div"), 400, 0), "overflowing columns are shrunk")
+}
+
+func TestRenderRow_SideBySide(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	doc, _ := html.Parse(strings.NewReader(`<div class="row">
+		<div class="col" style="width: 50%"><p>One</p><p>Two</p><p>Three</p></div>
+		<div class="col" style="width: 50%"><p>Only</p></div>
+	</div>`))
+
+	r.walk(doc)
+
+	paragraph := r.FontSize.P*4/3 + 4
+	assert.InDelta(t, 50+3*paragraph, r.y, 0.01, "content continues below the tallest column")
+	assert.Equal(t, 50.0, r.left)
+	assert.Equal(t, 495.0, r.width)
+	assert.False(t, r.inRow)
+}
+
+func TestRenderHTMLLikeToBuffer_RowMovedToNextPage(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+
+	var body strings.Builder
+	for i := 0; i < 34; i++ {
+		body.WriteString("<p>Filler</p>")
+	}
+	body.WriteString(`<div class="row" id="kpis">
+		<div class="col"><p>Revenue</p><p>1.2M</p></div>
+		<div class="col"><p>Margin</p><p>34%</p></div></div>`)
+
+	_, err := r.RenderHTMLLikeToBuffer(body.String())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, r.anchorPages["kpis"])
+}
+
+func TestRenderHTMLLikeToBuffer_RowTallerThanPage(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+
+	body := `<p id="before">Before</p><div class="row">
+		<div class="col" style="width: 30%">` + strings.Repeat("<p>Line</p>", 80) + `<p id="left-end">End</p></div>
+		<div class="col" style="width: 70%"><p id="right">Right column</p></div></div>
+		<p id="after">After</p>`
+
+	_, err := r.RenderHTMLLikeToBuffer(body)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, r.anchorPages["before"], "the row starts where it is instead of on an empty page")
+	assert.Equal(t, 3, r.anchorPages["left-end"], "the first column breaks across pages")
+	assert.Equal(t, 3, r.anchorPages["right"], "the second column is stacked below the first")
+	assert.Equal(t, 3, r.anchorPages["after"])
+	assert.LessOrEqual(t, r.y, contentLimit)
+}
@@ -0,0 +1,179 @@
+// File: renderer/running.go
+package core
+
+import (
+	"log"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	// headerTop is where a running header starts, above the content area.
+	headerTop = 15.0
+
+	// footerTop is where a running footer starts, below the content area.
+	footerTop = contentLimit + 10
+)
+
+// runningBlock holds the variants of a running header or footer. The first
+// variant is used on page 1, odd and even on the matching pages, and all on
+// any page without a more specific variant.
+type runningBlock struct {
+	first, odd, even, all *html.Node
+}
+
+// empty reports whether the block has no variants.
+func (b runningBlock) empty() bool {
+	return b.first == nil && b.odd == nil && b.even == nil && b.all == nil
+}
+
+// forPage returns the variant drawn on the given physical page, or nil.
+func (b runningBlock) forPage(page int) *html.Node {
+	switch {
+	case page == 1 && b.first != nil:
+		return b.first
+	case page%2 == 1 && b.odd != nil:
+		return b.odd
+	case page%2 == 0 && b.even != nil:
+		return b.even
+	}
+	return b.all
+}
+
+// set stores n as the variant named by its data-page attribute
+// ("first", "odd" or "even"); any other value makes it the default variant.
+func (b *runningBlock) set(n *html.Node) {
+	switch strings.ToLower(strings.TrimSpace(getAttr(n, "data-page"))) {
+	case "first":
+		b.first = n
+	case "odd":
+		b.odd = n
+	case "even":
+		b.even = n
+	default:
+		b.all = n
+	}
+}
+
+// parseRunningTemplate parses an HTML fragment configured through
+// RendererFactory.WithHeaderTemplate or WithFooterTemplate. Elements named tag
+// in the fragment are read as variants; a fragment without them is used as
+// the default variant as a whole.
+func parseRunningTemplate(fragment, tag string) runningBlock {
+	var block runningBlock
+	if strings.TrimSpace(fragment) == "" {
+		return block
+	}
+	doc, err := html.Parse(strings.NewReader(fragment))
+	if err != nil {
+		log.Println("Running template skipped:", err)
+		return block
+	}
+	for _, n := range findElements(doc, tag) {
+		block.set(n)
+	}
+	if body := findElements(doc, "body"); block.empty() && len(body) > 0 {
+		block.all = body[0]
+	}
+	return block
+}
+
+// extractRunningBlocks removes the <header> and <footer> elements from the
+// document, so they are not laid out as content, and stores them as running
+// blocks. Blocks in the document replace the same variants configured on the
+// renderer. The document's <title> is kept for the {title} placeholder.
+func (r *Renderer) extractRunningBlocks(doc *html.Node) {
+	r.header = parseRunningTemplate(r.HeaderTemplate, "header")
+	r.footer = parseRunningTemplate(r.FooterTemplate, "footer")
+
+	for _, n := range findElements(doc, "header") {
+		r.header.set(n)
+		n.Parent.RemoveChild(n)
+	}
+	for _, n := range findElements(doc, "footer") {
+		r.footer.set(n)
+		n.Parent.RemoveChild(n)
+	}
+	if title := findElements(doc, "title"); len(title) > 0 {
+		r.title = GetTextContent(title[0])
+	}
+}
+
+// drawRunningBlocks draws the running header and footer on every page once
+// layout is complete, so that {total} is known. Each block is laid out like
+// regular content in the page margin, with body text at the footer font size.
+func (r *Renderer) drawRunningBlocks() {
+	if r.header.empty() && r.footer.empty() {
+		return
+	}
+
+	fontSize := r.FontSize
+	y := r.y
+	r.FontSize.P = r.FontSize.Footer
+	r.inRunningBlock = true
+	defer func() {
+		r.FontSize = fontSize
+		r.y = y
+		r.inRunningBlock = false
+		_ = r.pdf.SetPage(r.pageNumber)
+	}()
+
+	for page := 1; page <= r.pageNumber; page++ {
+		if err := r.pdf.SetPage(page); err != nil {
+			log.Println("Running header/footer skipped:", err)
+			continue
+		}
+		r.beginArtifact()
+		if n := r.header.forPage(page); n != nil {
+			r.y = headerTop
+			r.walk(r.expandPlaceholders(n, page))
+		}
+		if n := r.footer.forPage(page); n != nil {
+			r.y = footerTop
+			r.walk(r.expandPlaceholders(n, page))
+		}
+		r.endArtifact()
+	}
+}
+
+// expandPlaceholders returns a copy of n in which {page}, {total}, {timestamp}
+// and {title} are replaced in all text.
+func (r *Renderer) expandPlaceholders(n *html.Node, page int) *html.Node {
+	replacer := strings.NewReplacer(
+		"{page}", r.PageNumbers.pageLabel(page),
+		"{total}", r.PageNumbers.pageLabel(r.pageNumber),
+		"{timestamp}", r.TopRightTimestamp,
+		"{title}", r.title,
+	)
+	return cloneNode(n, replacer)
+}
+
+// cloneNode deep-copies n, applying replacer to every text node.
+func cloneNode(n *html.Node, replacer *strings.Replacer) *html.Node {
+	c := &html.Node{
+		Type:     n.Type,
+		DataAtom: n.DataAtom,
+		Data:     n.Data,
+		Attr:     append([]html.Attribute(nil), n.Attr...),
+	}
+	if n.Type == html.TextNode {
+		c.Data = replacer.Replace(n.Data)
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		c.AppendChild(cloneNode(child, replacer))
+	}
+	return c
+}
+
+// findElements returns all elements with the given tag name, in document order.
+func findElements(n *html.Node, tag string) []*html.Node {
+	var found []*html.Node
+	if n.Type == html.ElementNode && n.Data == tag {
+		found = append(found, n)
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		found = append(found, findElements(c, tag)...)
+	}
+	return found
+}
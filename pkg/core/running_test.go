@@ -0,0 +1,76 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/html"
+)
+
+func TestParseRunningTemplate_Variants(t *testing.T) {
+	block := parseRunningTemplate(`
+		<footer data-page="first"><p>Cover</p></footer>
+		<footer data-page="even"><p>Even</p></footer>
+		<footer><p>Default</p></footer>`, "footer")
+
+	assert.Equal(t, "Cover", GetTextContent(block.forPage(1)))
+	assert.Equal(t, "Even", GetTextContent(block.forPage(2)))
+	assert.Equal(t, "Default", GetTextContent(block.forPage(3)), "odd pages fall back to the default")
+}
+
+func TestParseRunningTemplate_PlainFragment(t *testing.T) {
+	block := parseRunningTemplate(`<p>Quarterly report</p>`, "header")
+	assert.Equal(t, "Quarterly report", GetTextContent(block.forPage(2)))
+
+	assert.True(t, parseRunningTemplate("  ", "header").empty())
+}
+
+func TestExtractRunningBlocks(t *testing.T) {
+	doc, _ := html.Parse(strings.NewReader(`<html><head><title>Sales</title></head><body>
+		<header><p>Doc header</p></header>
+		<p>Body</p>
+		<footer data-page="odd"><p>Odd footer</p></footer></body></html>`))
+
+	r := &Renderer{HeaderTemplate: `<p>Factory header</p>`, FooterTemplate: `<p>Factory footer</p>`}
+	r.extractRunningBlocks(doc)
+
+	assert.Equal(t, "Sales", r.title)
+	assert.Equal(t, "Doc header", GetTextContent(r.header.all), "document blocks replace configured ones")
+	assert.Equal(t, "Odd footer", GetTextContent(r.footer.forPage(3)))
+	assert.Equal(t, "Factory footer", GetTextContent(r.footer.forPage(2)))
+	assert.Nil(t, findNode(doc, "header"), "blocks are removed from the content")
+	assert.Nil(t, findNode(doc, "footer"))
+}
+
+func TestExpandPlaceholders(t *testing.T) {
+	doc, _ := html.Parse(strings.NewReader(`<p>{title} – {page} / {total} – {timestamp}</p>`))
+	r := &Renderer{pageNumber: 4, title: "Sales", TopRightTimestamp: "2024-01-02"}
+
+	expanded := r.expandPlaceholders(findNode(doc, "p"), 2)
+
+	assert.Equal(t, "Sales – 2 / 4 – 2024-01-02", GetTextContent(expanded))
+	assert.Equal(t, "{title} – {page} / {total} – {timestamp}", GetTextContent(findNode(doc, "p")), "the template is left untouched")
+}
+
+func TestRenderHTMLLikeToBuffer_RunningBlocks(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), false)
+	r.FooterTemplate = `<p style="text-align: center">Page {page} of {total}</p>`
+
+	var body strings.Builder
+	body.WriteString(`<header data-page="first"><h3>{title}</h3></header>`)
+	for i := 0; i < 80; i++ {
+		body.WriteString("<p>Lorem ipsum dolor sit amet, consectetur adipiscing elit.</p>")
+	}
+
+	_, err := r.RenderHTMLLikeToBuffer(body.String())
+	assert.NoError(t, err)
+	assert.Greater(t, r.pageNumber, 1)
+	assert.NotNil(t, r.header.first)
+	assert.NotNil(t, r.footer.all)
+	assert.False(t, r.inRunningBlock)
+	assert.Equal(t, defaultFontSizes(), r.FontSize, "font sizes are restored after drawing")
+}
@@ -0,0 +1,359 @@
+// File: renderer/signature.go
+package core
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	// defaultSignatureWidth and defaultSignatureHeight size a <signature> box
+	// without width or height styles.
+	defaultSignatureWidth  = 200.0
+	defaultSignatureHeight = 50.0
+
+	// signatureFieldFlags marks a signed field as printable and locked.
+	signatureFieldFlags = 132
+
+	// byteRangePlaceholder reserves room for the /ByteRange of a signature,
+	// which is only known once the document has been written.
+	byteRangePlaceholder = "[0 0000000000 0000000000 0000000000]"
+)
+
+// signatureField is a signature box reserved by a <signature> element.
+type signatureField struct {
+	name          string  // Field name, from the name attribute.
+	page          int     // Page the box is on.
+	x, y          float64 // Top-left corner of the box.
+	width, height float64 // Size of the box.
+}
+
+// renderSignatureBox reserves a box for a visible signature at the current
+// position, sized by the element's width and height styles and placed by its
+// text-align. The box becomes an empty signature field that SignPDF fills.
+func (r *Renderer) renderSignatureBox(n *html.Node) {
+	width, height := min(defaultSignatureWidth, r.width), defaultSignatureHeight
+	if v, ok := styleProperty(n, "width"); ok {
+		if w, ok := parseSize(v, r.width); ok && w > 0 {
+			width = min(w, r.width)
+		}
+	}
+	if v, ok := styleProperty(n, "height"); ok {
+		if h, ok := parseLength(v); ok && h > 0 {
+			height = h
+		}
+	}
+	r.checkPageBreak(height)
+
+	x := r.left
+	switch textAlign(n) {
+	case AlignCenter:
+		x += (r.width - width) / 2
+	case AlignRight:
+		x += r.width - width
+	}
+	if !r.outOfFlow() {
+		name := getAttr(n, "name")
+		if name == "" {
+			name = fmt.Sprintf("Signature%d", len(r.signatureFields)+1)
+		}
+		r.signatureFields = append(r.signatureFields, signatureField{
+			name: name, page: r.pageNumber, x: x, y: r.y, width: width, height: height,
+		})
+	}
+	r.y += height + 10
+}
+
+// addSignatureFields adds an empty signature field for every reserved
+// signature box to a rendered PDF.
+func addSignatureFields(pdf []byte, fields []signatureField) ([]byte, error) {
+	if bytes.Contains(pdf, []byte("/Encrypt")) {
+		return nil, errors.New("signature fields cannot be added to password-protected PDFs")
+	}
+	u, err := newPDFUpdate(pdf)
+	if err != nil {
+		return nil, err
+	}
+	pages, err := u.pages()
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int
+	for _, f := range fields {
+		if f.page < 1 || f.page > len(pages) {
+			continue
+		}
+		pageTop, err := u.pageTop(pages[f.page-1])
+		if err != nil {
+			return nil, err
+		}
+		top := pageTop - f.y
+		appearance := u.add(formXObject(f.width, f.height, nil, nil))
+		widget := u.add(formatDict([]dictEntry{
+			{key: "Type", value: []byte("/Annot")},
+			{key: "Subtype", value: []byte("/Widget")},
+			{key: "FT", value: []byte("/Sig")},
+			{key: "T", value: []byte(pdfTextString(f.name))},
+			{key: "Rect", value: []byte(fmt.Sprintf("[%.2f %.2f %.2f %.2f]", f.x, top-f.height, f.x+f.width, top))},
+			{key: "P", value: []byte(ref(pages[f.page-1]))},
+			{key: "F", value: []byte("4")},
+			{key: "AP", value: []byte("<< /N " + ref(appearance) + " >>")},
+		}))
+		if err := u.addAnnotation(pages[f.page-1], widget); err != nil {
+			return nil, err
+		}
+		ids = append(ids, widget)
+	}
+	err = u.addFormFields(ids, func(form []dictEntry) []dictEntry {
+		return dictSet(form, "SigFlags", "1")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return u.bytes(), nil
+}
+
+// SignPDF signs a PDF produced by RenderHTMLLikeToBuffer with a PKCS#7
+// detached signature (adbe.pkcs7.detached), appended as an incremental update.
+//
+// The signature goes into the <signature> box named by opts.Field, or the
+// first unsigned one when Field is empty; its appearance shows the signer,
+// date, and reason. Without a box, an invisible signature is added to the
+// first page. Password-protected documents cannot be signed.
+func SignPDF(pdf []byte, opts SignOptions) ([]byte, error) {
+	if opts.Signer == nil || len(opts.Certificates) == 0 {
+		return nil, errors.New("a signer and its certificate are required")
+	}
+	if bytes.Contains(pdf, []byte("/Encrypt")) {
+		return nil, errors.New("password-protected PDFs cannot be signed")
+	}
+	signingTime := opts.SigningTime
+	if signingTime.IsZero() {
+		signingTime = time.Now()
+	}
+
+	u, err := newPDFUpdate(pdf)
+	if err != nil {
+		return nil, err
+	}
+	widget, field, err := u.unsignedField(opts.Field)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reserve room for the signature and every certificate, hex encoded.
+	reserve := 8192
+	for _, c := range opts.Certificates {
+		reserve += len(c.Raw)
+	}
+	signer := opts.Certificates[0].Subject.CommonName
+	if signer == "" {
+		signer = opts.Certificates[0].Subject.String()
+	}
+	sig := []dictEntry{
+		{key: "Type", value: []byte("/Sig")},
+		{key: "Filter", value: []byte("/Adobe.PPKLite")},
+		{key: "SubFilter", value: []byte("/adbe.pkcs7.detached")},
+		{key: "ByteRange", value: []byte(byteRangePlaceholder)},
+		{key: "Contents", value: []byte("<" + strings.Repeat("0", 2*reserve) + ">")},
+		{key: "M", value: []byte(pdfDate(signingTime))},
+		{key: "Name", value: []byte(pdfTextString(signer))},
+	}
+	for _, e := range []struct{ key, value string }{
+		{"Reason", opts.Reason}, {"Location", opts.Location}, {"ContactInfo", opts.ContactInfo},
+	} {
+		if e.value != "" {
+			sig = append(sig, dictEntry{key: e.key, value: []byte(pdfTextString(e.value))})
+		}
+	}
+	sigID := u.add(formatDict(sig))
+
+	if widget == 0 {
+		pages, err := u.pages()
+		if err != nil {
+			return nil, err
+		}
+		if len(pages) == 0 {
+			return nil, errors.New("PDF has no pages")
+		}
+		widget = u.add(formatDict([]dictEntry{
+			{key: "Type", value: []byte("/Annot")},
+			{key: "Subtype", value: []byte("/Widget")},
+			{key: "FT", value: []byte("/Sig")},
+			{key: "T", value: []byte(pdfTextString("Signature"))},
+			{key: "Rect", value: []byte("[0 0 0 0]")},
+			{key: "P", value: []byte(ref(pages[0]))},
+			{key: "F", value: []byte(fmt.Sprint(signatureFieldFlags))},
+			{key: "V", value: []byte(ref(sigID))},
+		}))
+		if err := u.addAnnotation(pages[0], widget); err != nil {
+			return nil, err
+		}
+		err = u.addFormFields([]int{widget}, setSigFlags)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		field = dictSet(field, "V", ref(sigID))
+		field = dictSet(field, "F", fmt.Sprint(signatureFieldFlags))
+		if w, h := rectSize(dictGet(field, "Rect")); w > 0 && h > 0 {
+			var lines []string
+			if !u.isPDFA() {
+				// PDF/A does not allow the standard, non-embedded font used for
+				// the signer details, so only the frame is drawn there.
+				lines = append(lines, "Digitally signed by "+signer, "Date: "+signingTime.Format("2006-01-02 15:04:05 MST"))
+				if opts.Reason != "" {
+					lines = append(lines, "Reason: "+opts.Reason)
+				}
+			}
+			appearance := u.add(formXObject(w, h, lines, []byte("0.4 G 0.75 w")))
+			field = dictSet(field, "AP", "<< /N "+ref(appearance)+" >>")
+		}
+		u.set(widget, formatDict(field))
+		if err := u.addFormFields(nil, setSigFlags); err != nil {
+			return nil, err
+		}
+	}
+
+	out := u.bytes()
+	return fillSignature(out, sigID, opts, signingTime)
+}
+
+// fillSignature writes the byte range and PKCS#7 signature of the document
+// into the placeholders of signature dictionary sigID.
+func fillSignature(out []byte, sigID int, opts SignOptions, signingTime time.Time) ([]byte, error) {
+	start := bytes.LastIndex(out, []byte(fmt.Sprintf("\n%d 0 obj\n", sigID)))
+	if start < 0 {
+		return nil, errors.New("signature dictionary not found")
+	}
+	rangeAt := start + bytes.Index(out[start:], []byte(byteRangePlaceholder))
+	contentsAt := start + bytes.Index(out[start:], []byte("/Contents <")) + len("/Contents ")
+	contentsEnd := contentsAt + bytes.IndexByte(out[contentsAt:], '>') + 1
+
+	byteRange := fmt.Sprintf("[0 %010d %010d %010d]", contentsAt, contentsEnd, len(out)-contentsEnd)
+	copy(out[rangeAt:], byteRange)
+
+	h := sha256.New()
+	h.Write(out[:contentsAt])
+	h.Write(out[contentsEnd:])
+	signature, err := signPKCS7Detached(h.Sum(nil), opts.Signer, opts.Certificates, signingTime)
+	if err != nil {
+		return nil, err
+	}
+	encoded := hex.EncodeToString(signature)
+	if len(encoded) > contentsEnd-contentsAt-2 {
+		return nil, errors.New("signature does not fit into the reserved space")
+	}
+	copy(out[contentsAt+1:], encoded)
+	return out, nil
+}
+
+// setSigFlags marks the interactive form as containing signatures that must
+// only be appended to.
+func setSigFlags(form []dictEntry) []dictEntry {
+	return dictSet(form, "SigFlags", "3")
+}
+
+// unsignedField returns the first signature field without a value, or the
+// one named name. It returns zero when the document has none.
+func (u *pdfUpdate) unsignedField(name string) (int, []dictEntry, error) {
+	for _, id := range u.ids() {
+		dict, err := u.dict(id)
+		if err != nil || string(dictGet(dict, "FT")) != "/Sig" {
+			continue
+		}
+		if name != "" && string(dictGet(dict, "T")) != pdfTextString(name) {
+			continue
+		}
+		if dictGet(dict, "V") != nil {
+			if name != "" {
+				return 0, nil, fmt.Errorf("signature field %q is already signed", name)
+			}
+			continue
+		}
+		return id, dict, nil
+	}
+	if name != "" {
+		return 0, nil, fmt.Errorf("signature field %q not found", name)
+	}
+	return 0, nil, nil
+}
+
+// isPDFA reports whether the document declares PDF/A conformance through an
+// output intent.
+func (u *pdfUpdate) isPDFA() bool {
+	rootID, err := u.root()
+	if err != nil {
+		return false
+	}
+	catalog, err := u.dict(rootID)
+	return err == nil && bytes.Contains(dictGet(catalog, "OutputIntents"), []byte("/GTS_PDFA1"))
+}
+
+// formXObject returns a form XObject of the given size that strokes a frame
+// with the given graphics state, when set, and draws lines of text in
+// Helvetica. Without either it is empty.
+func formXObject(width, height float64, lines []string, frame []byte) []byte {
+	var content bytes.Buffer
+	if frame != nil {
+		fmt.Fprintf(&content, "q %s 0.5 0.5 %.2f %.2f re S Q\n", frame, width-1, height-1)
+	}
+	entries := []dictEntry{
+		{key: "Type", value: []byte("/XObject")},
+		{key: "Subtype", value: []byte("/Form")},
+		{key: "BBox", value: []byte(fmt.Sprintf("[0 0 %.2f %.2f]", width, height))},
+	}
+	if len(lines) > 0 {
+		size := min(8, (height-4)/float64(len(lines))/1.25)
+		fmt.Fprintf(&content, "BT /Helv %.2f Tf 0 g %.2f TL 4 %.2f Td", size, size*1.25, height-4-size)
+		for i, line := range lines {
+			if i > 0 {
+				content.WriteString(" T*")
+			}
+			fmt.Fprintf(&content, " (%s) Tj", winAnsiString(line))
+		}
+		content.WriteString(" ET\n")
+		entries = append(entries, dictEntry{
+			key:   "Resources",
+			value: []byte("<< /Font << /Helv << /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >> >> >>"),
+		})
+	}
+	return streamObject(entries, content.Bytes())
+}
+
+// winAnsiString escapes s for a literal string in a WinAnsi-encoded font,
+// replacing characters outside Latin-1 with '?'.
+func winAnsiString(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		switch {
+		case c == '(' || c == ')' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(c)
+		case c < 32 || c > 255:
+			b.WriteByte('?')
+		case c > 126:
+			fmt.Fprintf(&b, "\\%03o", c)
+		default:
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+// rectSize returns the width and height of a rectangle such as "[x1 y1 x2 y2]".
+func rectSize(rect []byte) (float64, float64) {
+	var x1, y1, x2, y2 float64
+	if _, err := fmt.Sscanf(string(bytes.Trim(rect, "[]")), "%f %f %f %f", &x1, &y1, &x2, &y2); err != nil {
+		return 0, 0
+	}
+	return max(x1, x2) - min(x1, x2), max(y1, y2) - min(y1, y2)
+}
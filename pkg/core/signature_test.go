@@ -0,0 +1,178 @@
+package core
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// selfSignedCert returns a self-signed certificate for key.
+func selfSignedCert(t *testing.T, key crypto.Signer) *x509.Certificate {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "Test Signer"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
+
+// verifyLastSignature checks that the last signature of pdf covers everything
+// but its own contents and was made by the embedded certificate.
+func verifyLastSignature(t *testing.T, pdf []byte, algo x509.SignatureAlgorithm) {
+	at := bytes.LastIndex(pdf, []byte("/ByteRange ["))
+	require.GreaterOrEqual(t, at, 0)
+	var a, b, c, d int
+	_, err := fmt.Sscanf(string(pdf[at+len("/ByteRange ["):]), "%d %d %d %d", &a, &b, &c, &d)
+	require.NoError(t, err)
+	assert.Equal(t, 0, a)
+	assert.Equal(t, len(pdf), c+d, "the byte range covers the whole file")
+
+	der, err := hex.DecodeString(string(pdf[b+1 : c-1]))
+	require.NoError(t, err)
+	var ci contentInfo
+	_, err = asn1.Unmarshal(der, &ci)
+	require.NoError(t, err)
+	assert.True(t, ci.ContentType.Equal(oidSignedData))
+	var sd signedData
+	_, err = asn1.Unmarshal(ci.Content.Bytes, &sd)
+	require.NoError(t, err)
+
+	certs, err := x509.ParseCertificates(sd.Certificates.Bytes)
+	require.NoError(t, err)
+	require.Len(t, certs, 1)
+
+	h := sha256.New()
+	h.Write(pdf[:b])
+	h.Write(pdf[c:])
+	attrs := append([]byte{0x31}, sd.SignerInfos[0].SignedAttrs.FullBytes[1:]...)
+	assert.True(t, bytes.Contains(attrs, h.Sum(nil)), "the message digest matches the signed bytes")
+	assert.NoError(t, certs[0].CheckSignature(algo, attrs, sd.SignerInfos[0].Signature))
+}
+
+func TestSignPDF_Invisible(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	buf, err := r.RenderHTMLLikeToBuffer(`<p>Certificate of completion</p>`)
+	require.NoError(t, err)
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	signed, err := SignPDF(buf.Bytes(), SignOptions{
+		Signer:       key,
+		Certificates: []*x509.Certificate{selfSignedCert(t, key)},
+		Reason:       "Approved",
+	})
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(signed, buf.Bytes()), "the signature is an incremental update")
+	assert.Contains(t, string(signed), "/SubFilter /adbe.pkcs7.detached")
+	assert.Contains(t, string(signed), "/SigFlags 3")
+	assert.Contains(t, string(signed), "/Rect [0 0 0 0]")
+	verifyLastSignature(t, signed, x509.ECDSAWithSHA256)
+}
+
+func TestSignPDF_VisibleBox(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	buf, err := r.RenderHTMLLikeToBuffer(`<p>Invoice</p><signature name="approver" style="width: 150pt; height: 40pt; text-align: right"></signature>`)
+	require.NoError(t, err)
+	require.Len(t, r.signatureFields, 1)
+	field := r.signatureFields[0]
+	assert.Equal(t, 545.0-150, field.x)
+	assert.Contains(t, buf.String(), "/FT /Sig", "an empty signature field is added when rendering")
+
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	opts := SignOptions{Signer: key, Certificates: []*x509.Certificate{selfSignedCert(t, key)}, Field: "approver"}
+	signed, err := SignPDF(buf.Bytes(), opts)
+	require.NoError(t, err)
+	assert.Contains(t, string(signed), "(Digitally signed by Test Signer) Tj")
+	assert.Contains(t, string(signed), fmt.Sprintf("/Rect [395.00 %.2f 545.00 %.2f]", pageHeight-field.y-40, pageHeight-field.y))
+	verifyLastSignature(t, signed, x509.SHA256WithRSA)
+
+	_, err = SignPDF(signed, opts)
+	assert.ErrorContains(t, err, "already signed")
+}
+
+func TestSignPDF_PDFA(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	r.PDFA = true
+	buf, err := r.RenderHTMLLikeToBuffer(`<signature></signature>`)
+	require.NoError(t, err)
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	signed, err := SignPDF(buf.Bytes(), SignOptions{Signer: key, Certificates: []*x509.Certificate{selfSignedCert(t, key)}})
+	require.NoError(t, err)
+	assert.NotContains(t, string(signed), "/Helvetica", "PDF/A appearances use no standard fonts")
+	verifyLastSignature(t, signed, x509.ECDSAWithSHA256)
+}
+
+func TestSignPDF_Errors(t *testing.T) {
+	_, err := SignPDF(minimalPDF(), SignOptions{})
+	assert.ErrorContains(t, err, "certificate are required")
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	opts := SignOptions{Signer: key, Certificates: []*x509.Certificate{selfSignedCert(t, key)}, Field: "missing"}
+	_, err = SignPDF(minimalPDF(), opts)
+	assert.ErrorContains(t, err, `signature field "missing" not found`)
+
+	opts.Field = ""
+	_, err = SignPDF([]byte("%PDF-1.7\n/Encrypt 3 0 R\n"), opts)
+	assert.ErrorContains(t, err, "password-protected")
+}
+
+func TestSignPDF_MalformedPDFs(t *testing.T) {
+	catalog := "<< /Type /Catalog /Pages 2 0 R >>"
+	page := "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] >>"
+	valid := buildPDF([]string{catalog, "<< /Type /Pages /Kids [3 0 R] /Count 1 >>", page}, "", "<< /Size 4 /Root 1 0 R >>")
+	tests := []struct {
+		name string
+		pdf  []byte
+		err  string
+	}{
+		{"truncated", valid[:len(valid)/2], "startxref"},
+		{"truncated xref", valid[:bytes.Index(valid, []byte("trailer"))+3], "startxref"},
+		{"cyclic prev", buildPDF([]string{catalog, "<< /Type /Pages /Kids [] >>"}, "", "<< /Size 3 /Root 1 0 R /Prev XREF >>"), "cyclic /Prev"},
+		{"prev out of range", buildPDF([]string{catalog}, "", "<< /Size 2 /Root 1 0 R /Prev -20 >>"), "invalid /Prev"},
+		{"cyclic kids", buildPDF([]string{catalog, "<< /Type /Pages /Kids [2 0 R] >>"}, "", "<< /Size 3 /Root 1 0 R >>"), "referenced more than once"},
+		{"negative count", buildPDF([]string{catalog}, "0 -1", "<< /Size 2 /Root 1 0 R >>"), "malformed cross-reference"},
+		{"count too large", buildPDF([]string{catalog}, "0 9", "<< /Size 2 /Root 1 0 R >>"), "malformed cross-reference"},
+		{"offset out of range", bytes.Replace(valid, []byte("0000000009 00000 n"), []byte("9999999999 00000 n"), 1), "invalid offset"},
+	}
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	opts := SignOptions{Signer: key, Certificates: []*x509.Certificate{selfSignedCert(t, key)}}
+	_, err := SignPDF(valid, opts)
+	require.NoError(t, err)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := SignPDF(tt.pdf, opts)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,252 @@
+// File: renderer/table.go
+package core
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// tableRowKind classifies a table row by the section it belongs to,
+// which decides the fill and font applied when it is rendered.
+type tableRowKind int
+
+const (
+	tableRowBody tableRowKind = iota
+	tableRowHeader
+	tableRowFooter
+)
+
+// DefaultTableStyle returns the table style used when none is configured:
+// collapsed 1pt black borders and no fills.
+func DefaultTableStyle() TableStyle {
+	return TableStyle{
+		Border:      TableBorderCollapse,
+		BorderWidth: 1,
+		BorderColor: Color{},
+		FooterBold:  true,
+	}
+}
+
+// tableProgress is what the rows of a table share while they are rendered.
+type tableProgress struct {
+	bodyRows int     // Body rows rendered so far, so that zebra striping alternates correctly.
+	rulePage int     // Page of the last horizontal border drawn.
+	ruleY    float64 // Position of the last horizontal border drawn.
+}
+
+// renderTable walks through the table node and renders its rows.
+func (r *Renderer) renderTable(n *html.Node) {
+	r.walkTableRows(n, tableRowBody, &tableProgress{})
+	r.y += 10
+}
+
+// walkTableRows traverses table rows and renders each. The row kind is taken
+// from the enclosing <thead>, <tbody>, or <tfoot> section.
+func (r *Renderer) walkTableRows(n *html.Node, kind tableRowKind, progress *tableProgress) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		if r.measured() {
+			return
+		}
+		switch c.Data {
+		case "tr":
+			rowKind := kind
+			if rowKind == tableRowBody && isHeaderRow(c) {
+				rowKind = tableRowHeader
+			}
+			striped := false
+			if rowKind == tableRowBody {
+				striped = progress.bodyRows%2 == 1
+				progress.bodyRows++
+			}
+			r.checkPageBreak(30)
+			tag := r.beginTag("TR", "")
+			r.renderTableRow(c, rowKind, striped, progress)
+			r.endTag(tag)
+		case "thead":
+			tag := r.beginTag("THead", "")
+			r.walkTableRows(c, tableRowHeader, progress)
+			r.endTag(tag)
+		case "tfoot":
+			tag := r.beginTag("TFoot", "")
+			r.walkTableRows(c, tableRowFooter, progress)
+			r.endTag(tag)
+		case "tbody":
+			tag := r.beginTag("TBody", "")
+			r.walkTableRows(c, tableRowBody, progress)
+			r.endTag(tag)
+		default:
+			r.walkTableRows(c, kind, progress)
+		}
+	}
+}
+
+// renderTableRow renders a single table row with dynamic height and column width.
+// Cell content goes through the same styled-chunk layout as paragraphs, so bold,
+// italic, colored and linked text, <br> line breaks, and inline images are kept.
+// The row background, font weight, and borders follow the renderer's TableStyle.
+func (r *Renderer) renderTableRow(tr *html.Node, kind tableRowKind, striped bool, progress *tableProgress) {
+	lineHeight := 14.0
+	numCols := r.countColumns(tr)
+	if numCols == 0 {
+		return
+	}
+	colWidth := r.width / float64(numCols)
+
+	bold := kind == tableRowHeader || (kind == tableRowFooter && r.TableStyle.FooterBold)
+
+	rowHeight := lineHeight
+	cells := [][]textLine{}
+	aligns := []Alignment{}
+	roles := []string{}
+	for td := tr.FirstChild; td != nil; td = td.NextSibling {
+		if td.Type == html.ElementNode && (td.Data == "td" || td.Data == "th") {
+			chunks := GetStyledTextChunks(td)
+			if bold {
+				for i := range chunks {
+					chunks[i].Bold = true
+				}
+			}
+			lines := r.layoutChunks(chunks, colWidth-8, r.FontSize.P, lineHeight)
+			cells = append(cells, lines)
+			aligns = append(aligns, textAlign(td))
+			roles = append(roles, strings.ToUpper(td.Data))
+			rowHeight = max(rowHeight, linesHeight(lines))
+		}
+	}
+	r.checkPageBreak(rowHeight)
+
+	inset := 0.0
+	if r.TableStyle.Border == TableBorderSeparate {
+		inset = r.TableStyle.CellSpacing / 2
+	}
+
+	startY := r.y
+	r.beginArtifact()
+	if fill := r.TableStyle.rowFill(kind, striped); fill != nil {
+		r.pdf.SetFillColor(fill.R, fill.G, fill.B)
+		if inset > 0 {
+			for i := range cells {
+				x := r.left + float64(i)*colWidth
+				r.pdf.RectFromUpperLeftWithStyle(x+inset, startY+inset, colWidth-2*inset, rowHeight-2*inset, "F")
+			}
+		} else {
+			r.pdf.RectFromUpperLeftWithStyle(r.left, startY, colWidth*float64(numCols), rowHeight, "F")
+		}
+		// Text is painted with the fill color, so reset it before drawing cells.
+		r.pdf.SetFillColor(0, 0, 0)
+	}
+	r.endArtifact()
+
+	x := r.left
+	for i, lines := range cells {
+		tag := r.beginTag(roles[i], "")
+		y := startY + 2
+		for j, line := range lines {
+			r.drawAlignedLine(line, x+4, y, colWidth-8, r.FontSize.P, aligns[i], j == len(lines)-1)
+			y += line.height
+		}
+		r.endTag(tag)
+		x += colWidth
+	}
+
+	// In collapse mode the top border is the previous row's bottom border,
+	// unless the row starts a page or column.
+	top := progress.rulePage != r.pageNumber || progress.ruleY != startY
+	r.beginArtifact()
+	r.drawRowBorders(startY, colWidth, rowHeight, numCols, inset, top)
+	r.endArtifact()
+	progress.rulePage, progress.ruleY = r.pageNumber, startY+rowHeight
+	r.y += rowHeight
+}
+
+// linesHeight returns the combined height of laid-out lines.
+func linesHeight(lines []textLine) float64 {
+	total := 0.0
+	for _, line := range lines {
+		total += line.height
+	}
+	return total
+}
+
+// drawRowBorders draws the borders of a row according to the TableStyle border
+// mode: separate mode outlines each cell, while collapse mode draws every grid
+// line once, including the top one only when top is set.
+func (r *Renderer) drawRowBorders(y, colWidth, rowHeight float64, numCols int, inset float64, top bool) {
+	style := r.TableStyle
+	if style.Border == TableBorderNone {
+		return
+	}
+	width := style.BorderWidth
+	if width <= 0 {
+		width = 1
+	}
+	r.pdf.SetLineWidth(width)
+	r.pdf.SetStrokeColor(style.BorderColor.R, style.BorderColor.G, style.BorderColor.B)
+
+	if style.Border == TableBorderSeparate {
+		x := r.left
+		for i := 0; i < numCols; i++ {
+			r.pdf.RectFromUpperLeftWithStyle(x+inset, y+inset, colWidth-2*inset, rowHeight-2*inset, "D")
+			x += colWidth
+		}
+	} else {
+		right := r.left + colWidth*float64(numCols)
+		if top {
+			r.pdf.Line(r.left, y, right, y)
+		}
+		r.pdf.Line(r.left, y+rowHeight, right, y+rowHeight)
+		for i := 0; i <= numCols; i++ {
+			x := r.left + float64(i)*colWidth
+			r.pdf.Line(x, y, x, y+rowHeight)
+		}
+	}
+
+	r.pdf.SetLineWidth(1)
+	r.pdf.SetStrokeColor(0, 0, 0)
+}
+
+// rowFill returns the background color for a row of the given kind, or nil
+// when the row should not be filled.
+func (s TableStyle) rowFill(kind tableRowKind, striped bool) *Color {
+	switch kind {
+	case tableRowHeader:
+		return s.HeaderFill
+	case tableRowFooter:
+		return s.FooterFill
+	default:
+		if striped {
+			return s.StripeFill
+		}
+		return nil
+	}
+}
+
+// isHeaderRow reports whether every cell in the row is a <th> element.
+func isHeaderRow(tr *html.Node) bool {
+	found := false
+	for td := tr.FirstChild; td != nil; td = td.NextSibling {
+		if td.Type != html.ElementNode {
+			continue
+		}
+		if td.Data != "th" {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+// countColumns counts how many <td> or <th> elements are in a given <tr>.
+func (r *Renderer) countColumns(tr *html.Node) int {
+	count := 0
+	for td := tr.FirstChild; td != nil; td = td.NextSibling {
+		if td.Type == html.ElementNode && (td.Data == "td" || td.Data == "th") {
+			count++
+		}
+	}
+	return count
+}
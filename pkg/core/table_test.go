@@ -0,0 +1,181 @@
+package core
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/html"
+)
+
+func findNode(n *html.Node, tag string) *html.Node {
+	if n.Type == html.ElementNode && n.Data == tag {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findNode(c, tag); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func TestIsHeaderRow(t *testing.T) {
+	doc, _ := html.Parse(strings.NewReader(`<table>
+		<tr> <th>A</th> <th>B</th> </tr>
+	</table>`))
+	assert.True(t, isHeaderRow(findNode(doc, "tr")))
+
+	doc, _ = html.Parse(strings.NewReader(`<table><tr><th>A</th><td>B</td></tr></table>`))
+	assert.False(t, isHeaderRow(findNode(doc, "tr")))
+}
+
+func TestTableStyle_RowFill(t *testing.T) {
+	header := &Color{R: 200}
+	stripe := &Color{G: 200}
+	footer := &Color{B: 200}
+	style := TableStyle{HeaderFill: header, StripeFill: stripe, FooterFill: footer}
+
+	assert.Equal(t, header, style.rowFill(tableRowHeader, false))
+	assert.Equal(t, footer, style.rowFill(tableRowFooter, true))
+	assert.Equal(t, stripe, style.rowFill(tableRowBody, true))
+	assert.Nil(t, style.rowFill(tableRowBody, false))
+}
+
+func TestRenderHTMLLikeToBuffer_StyledTable(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+
+	styles := []TableStyle{
+		DefaultTableStyle(),
+		{
+			HeaderFill:  &Color{R: 220, G: 220, B: 220},
+			StripeFill:  &Color{R: 245, G: 245, B: 245},
+			FooterFill:  &Color{R: 230, G: 230, B: 250},
+			FooterBold:  true,
+			Border:      TableBorderSeparate,
+			BorderWidth: 0.5,
+			BorderColor: Color{R: 120, G: 120, B: 120},
+			CellSpacing: 2,
+		},
+		{Border: TableBorderNone},
+	}
+
+	for _, style := range styles {
+		t.Run(string(style.Border), func(t *testing.T) {
+			r, _ := NewRenderer(defaultFontSizes(), false)
+			r.TableStyle = style
+			r.pdf.SetNoCompression()
+
+			buf, err := r.RenderHTMLLikeToBuffer(`<table>
+				<thead><tr><th>Item</th><th>Cost</th></tr></thead>
+				<tbody>
+					<tr><td>A</td><td>1</td></tr>
+					<tr><td>B</td><td>2</td></tr>
+				</tbody>
+				<tfoot><tr><td>Total</td><td>3</td></tr></tfoot>
+			</table>`)
+			assert.NoError(t, err)
+
+			out := buf.String()
+			if style.HeaderFill != nil {
+				assert.Contains(t, out, "0.863 0.863 0.863 rg")
+				assert.Contains(t, out, "0.961 0.961 0.961 rg")
+			}
+			switch style.Border {
+			case TableBorderNone:
+				assert.NotContains(t, out, " re S")
+				assert.NotContains(t, out, " l S")
+			case TableBorderSeparate:
+				assert.Equal(t, 8, strings.Count(out, " re S"), "every cell is outlined")
+			default:
+				// Four rows of two cells: one top line, a bottom line per row,
+				// and three vertical segments per row, each drawn once.
+				assert.NotContains(t, out, " re S")
+				assert.Equal(t, 1+4+4*3, strings.Count(out, " l S"))
+			}
+		})
+	}
+}
+
+func TestRenderHTMLLikeToBuffer_TableCellInlineFormatting(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), false)
+
+	img := `data:image/png;base64,iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mP8z8BQDwAEhQGAhKmMIQAAAABJRU5ErkJggg==`
+	buf, err := r.RenderHTMLLikeToBuffer(`<table>
+		<tr><th>Check</th><th>Status</th></tr>
+		<tr>
+			<td>Disk <strong>usage</strong> on <em>node-1</em><br>second line</td>
+			<td><img src="` + img + `" width="10" height="10"> <span style="color: green">passed</span></td>
+		</tr>
+	</table>`)
+	require.NoError(t, err)
+
+	runs := map[string]textRun{}
+	for _, run := range pageRuns(t, buf.Bytes())[0] {
+		runs[strings.TrimSpace(run.text)] = run
+	}
+	for _, text := range []string{"Check", "Disk", "usage", "node-1", "second line", "passed"} {
+		require.Contains(t, runs, text)
+	}
+
+	// The strong run shares the bold font of the header cells and the em run
+	// has a third font, while the text around them stays regular.
+	assert.Equal(t, runs["Check"].font, runs["usage"].font)
+	assert.NotEqual(t, runs["Disk"].font, runs["usage"].font)
+	assert.NotEqual(t, runs["Disk"].font, runs["node-1"].font)
+	assert.NotEqual(t, runs["usage"].font, runs["node-1"].font)
+	assert.Equal(t, runs["Disk"].font, runs["second line"].font)
+
+	// The line break starts a second line at the left edge of the cell.
+	assert.Equal(t, runs["Disk"].x, runs["second line"].x)
+	assert.Less(t, runs["second line"].y, runs["Disk"].y)
+	assert.Equal(t, runs["Disk"].y, runs["usage"].y)
+	assert.Greater(t, runs["usage"].x, runs["Disk"].x)
+
+	// The image is drawn at the start of the second cell, on the first line,
+	// with the text following it.
+	content := pageContents(t, buf.Bytes())[0]
+	m := regexp.MustCompile(`10\.00 0 0\s+10\.00 ([\d.]+) ([\d.]+) cm /I\d+ Do`).FindStringSubmatch(content)
+	require.NotNil(t, m, "image XObject is drawn at 10x10")
+	x, _ := strconv.ParseFloat(m[1], 64)
+	y, _ := strconv.ParseFloat(m[2], 64)
+	assert.Greater(t, x, runs["node-1"].x, "image is in the second column")
+	assert.Less(t, x, runs["passed"].x)
+	assert.Less(t, y, runs["Check"].y, "image is in the body row")
+	assert.Greater(t, y, runs["second line"].y)
+}
+
+func TestRenderHTMLLikeToBuffer_CollapsedBordersAcrossPages(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	buf, err := r.RenderHTMLLikeToBuffer("<table>" + strings.Repeat("<tr><td>A</td><td>B</td></tr>", 60) + "</table>")
+	require.NoError(t, err)
+
+	line := regexp.MustCompile(`([\d.]+) ([\d.]+) m ([\d.]+) ([\d.]+) l S`)
+	contents := pageContents(t, buf.Bytes())
+	require.Greater(t, len(contents), 1)
+	for i, content := range contents {
+		horizontal, vertical := 0, 0
+		for _, m := range line.FindAllStringSubmatch(content, -1) {
+			if m[2] == m[4] {
+				horizontal++
+			} else {
+				vertical++
+			}
+		}
+		// Every row on the page has three vertical segments and a bottom
+		// line; the first row on the page also has a top line.
+		require.Greater(t, vertical, 0, "page %d", i+1)
+		assert.Equal(t, vertical/3+1, horizontal, "page %d", i+1)
+	}
+}
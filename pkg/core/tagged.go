@@ -0,0 +1,503 @@
+// File: renderer/tagged.go
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// structElem is an element of the structure tree of a tagged PDF, recorded
+// while the document is laid out.
+type structElem struct {
+	role   string // Standard structure type, e.g. "P", "H1" or "TD".
+	parent int    // Index of the enclosing element, or -1 at the top level.
+	alt    string // Alternate description of a figure.
+}
+
+// structRoles maps the elements that become structure elements in tagged PDFs
+// to their standard structure types.
+var structRoles = map[string]string{
+	"h1": "H1", "h2": "H2", "h3": "H3", "h4": "H4", "h5": "H5", "h6": "H6",
+	"p":          "P",
+	"div":        "Div",
+	"section":    "Sect",
+	"article":    "Art",
+	"blockquote": "BlockQuote",
+	"table":      "Table",
+	"ul":         "L",
+	"ol":         "L",
+	"li":         "LI", // The label and content of an item are tagged by renderListItem.
+	"img":        "Figure",
+}
+
+// Marker kinds written into content streams by mark.
+const (
+	markBeginTag = iota + 1
+	markEndTag
+	markBeginArtifact
+	markEndArtifact
+)
+
+// tagMarkerPhase identifies marker operators. gopdf has no way to write
+// marked-content operators, so while laying out a tagged document the renderer
+// writes a dash pattern with this (invalid) phase wherever an element starts or
+// ends; tagPDF replaces them with the marked-content operators once the
+// document has been written. The renderer counts the markers it writes, so a
+// change in how gopdf writes them fails tagging instead of going unnoticed.
+const tagMarkerPhase = -7281
+
+// linkTag is the Link structure element of a link annotation, in the order
+// the annotations were added to their page.
+type linkTag struct {
+	page int // Page the annotation is on.
+	elem int // Index of the Link element.
+}
+
+// mark writes a marker of the given kind into the current content stream.
+func (r *Renderer) mark(kind, elem int) {
+	if !r.Tagged || r.measuring {
+		return
+	}
+	r.pdf.SetCustomLineType([]float64{float64(kind), float64(elem)}, tagMarkerPhase)
+	r.markers++
+}
+
+// beginLink starts the Link structure element of a link to href, if the link
+// gets an annotation, and returns its index for endLink, or -1.
+func (r *Renderer) beginLink(href string) int {
+	if anchor, ok := strings.CutPrefix(href, "#"); ok && !r.anchorIDs[anchor] {
+		return -1
+	}
+	return r.beginTag("Link", "")
+}
+
+// endLink ends the Link element started by beginLink, once its annotation
+// has been added to the current page.
+func (r *Renderer) endLink(elem int) {
+	if elem < 0 {
+		return
+	}
+	r.linkTags = append(r.linkTags, linkTag{page: r.pageNumber, elem: elem})
+	r.endTag(elem)
+}
+
+// beginTag starts a structure element with the given role inside the element
+// currently open, and returns its index for endTag. Content laid out outside
+// the page flow is not tagged, and -1 is returned.
+func (r *Renderer) beginTag(role, alt string) int {
+	if !r.Tagged || r.outOfFlow() {
+		return -1
+	}
+	parent := -1
+	if len(r.openTags) > 0 {
+		parent = r.openTags[len(r.openTags)-1]
+	}
+	r.structElems = append(r.structElems, structElem{role: role, parent: parent, alt: alt})
+	elem := len(r.structElems) - 1
+	r.openTags = append(r.openTags, elem)
+	r.mark(markBeginTag, elem)
+	return elem
+}
+
+// endTag ends the structure element started by beginTag.
+func (r *Renderer) endTag(elem int) {
+	if elem < 0 {
+		return
+	}
+	r.openTags = r.openTags[:len(r.openTags)-1]
+	r.mark(markEndTag, elem)
+}
+
+// beginArtifact marks the content that follows, up to endArtifact, as a
+// pagination artifact (page decorations, running headers and footers, table
+// backgrounds and borders) that assistive technology skips.
+func (r *Renderer) beginArtifact() {
+	r.mark(markBeginArtifact, 0)
+}
+
+// endArtifact ends the artifact started by beginArtifact.
+func (r *Renderer) endArtifact() {
+	r.mark(markEndArtifact, 0)
+}
+
+// tagElement starts the structure element of n, if it has one, and returns
+// its index for endTag, or -1.
+func (r *Renderer) tagElement(n *html.Node) int {
+	role, ok := structRoles[n.Data]
+	if !ok {
+		return -1
+	}
+	return r.beginTag(role, getAttr(n, "alt"))
+}
+
+// documentLanguage returns the lang attribute of the <html> element.
+func documentLanguage(doc *html.Node) string {
+	for _, n := range findElements(doc, "html") {
+		return strings.TrimSpace(getAttr(n, "lang"))
+	}
+	return ""
+}
+
+// artifactElem stands for an artifact on the stack of open elements in tagPDF.
+const artifactElem = -2
+
+// taggedLayout is what the renderer records while laying out a tagged
+// document: its structure elements, the Link elements of link annotations,
+// and how many markers were written.
+type taggedLayout struct {
+	elems   []structElem
+	links   []linkTag
+	markers int
+}
+
+// tagPDF turns a PDF written by gopdf from a tagged layout into a tagged PDF.
+// Each page's content stream is rewritten with the markers replaced: content
+// inside a structure element becomes a marked-content sequence with an MCID,
+// and everything else an artifact. Link annotations are referenced from their
+// Link elements. The structure tree with its parent tree and the document
+// language are added, and the document is marked as tagged. As every content
+// stream is replaced, the document is written anew rather than updated
+// incrementally, which would keep the original streams in the file.
+func tagPDF(pdf []byte, layout taggedLayout, lang string) ([]byte, error) {
+	if bytes.Contains(pdf, []byte("/Encrypt")) {
+		return nil, errors.New("password-protected PDFs cannot be tagged")
+	}
+	u, err := newPDFUpdate(pdf)
+	if err != nil {
+		return nil, err
+	}
+	rootID, err := u.root()
+	if err != nil {
+		return nil, err
+	}
+	pages, err := u.pages()
+	if err != nil {
+		return nil, err
+	}
+	elems := layout.elems
+
+	// Object numbers are reserved up front, so that elements can refer to
+	// each other before they are written.
+	treeID, documentID, parentTreeID := u.add(nil), u.add(nil), u.add(nil)
+	elemIDs := make([]int, len(elems))
+	for i := range elems {
+		elemIDs[i] = u.add(nil)
+	}
+
+	// kids holds the children of each element in reading order: references
+	// to child elements and marked-content references. The last entry is
+	// the document element.
+	t := &contentTagger{elems: elems, elemIDs: elemIDs, kids: make([][]string, len(elems)+1), seen: make([]bool, len(elems))}
+	var parentTree strings.Builder
+	for key, pageID := range pages {
+		page, err := u.dict(pageID)
+		if err != nil {
+			return nil, err
+		}
+		contentID, ok := refID(dictGet(page, "Contents"))
+		if !ok {
+			return nil, fmt.Errorf("page %d has no single content stream", key+1)
+		}
+		_, data, err := u.stream(contentID)
+		if err != nil {
+			return nil, err
+		}
+		out, mcids, err := t.tagContent(data, pageID)
+		if err != nil {
+			return nil, fmt.Errorf("page %d: %w", key+1, err)
+		}
+
+		u.set(contentID, flateStreamObject(nil, out))
+		page = dictSet(page, "StructParents", strconv.Itoa(key))
+		page = dictSet(page, "Tabs", "/S")
+		u.set(pageID, formatDict(page))
+		fmt.Fprintf(&parentTree, "%d [%s] ", key, strings.Join(mcids, " "))
+	}
+	if t.markers != layout.markers {
+		return nil, fmt.Errorf("found %d of the %d structure markers written during layout", t.markers, layout.markers)
+	}
+
+	// Link annotations follow the parent tree entries of the pages.
+	key := len(pages)
+	links := layout.links
+	for p, pageID := range pages {
+		page, err := u.dict(pageID)
+		if err != nil {
+			return nil, err
+		}
+		for _, annotID := range arrayRefs(dictGet(page, "Annots")) {
+			annot, err := u.dict(annotID)
+			if err != nil {
+				return nil, err
+			}
+			if string(dictGet(annot, "Subtype")) != "/Link" {
+				continue
+			}
+			if len(links) == 0 || links[0].page != p+1 {
+				return nil, fmt.Errorf("link annotation %d on page %d has no structure element", annotID, p+1)
+			}
+			elem := links[0].elem
+			links = links[1:]
+			u.set(annotID, formatDict(dictSet(annot, "StructParent", strconv.Itoa(key))))
+			t.kids[elem] = append(t.kids[elem], fmt.Sprintf("<</Type /OBJR /Pg %s /Obj %s>>", ref(pageID), ref(annotID)))
+			fmt.Fprintf(&parentTree, "%d %s ", key, ref(elemIDs[elem]))
+			key++
+		}
+	}
+	if len(links) > 0 {
+		return nil, fmt.Errorf("%d link elements have no annotation", len(links))
+	}
+
+	for i, e := range elems {
+		dict := []dictEntry{
+			{key: "Type", value: []byte("/StructElem")},
+			{key: "S", value: []byte("/" + e.role)},
+			{key: "P", value: []byte(ref(documentID))},
+		}
+		if e.parent >= 0 {
+			dict = dictSet(dict, "P", ref(elemIDs[e.parent]))
+		}
+		if len(t.kids[i]) > 0 {
+			dict = dictSet(dict, "K", "["+strings.Join(t.kids[i], " ")+"]")
+		}
+		if e.alt != "" {
+			dict = dictSet(dict, "Alt", pdfTextString(e.alt))
+		}
+		u.set(elemIDs[i], formatDict(dict))
+	}
+	u.set(documentID, formatDict([]dictEntry{
+		{key: "Type", value: []byte("/StructElem")},
+		{key: "S", value: []byte("/Document")},
+		{key: "P", value: []byte(ref(treeID))},
+		{key: "K", value: []byte("[" + strings.Join(t.kids[len(elems)], " ") + "]")},
+	}))
+	u.set(parentTreeID, formatDict([]dictEntry{
+		{key: "Nums", value: []byte("[" + strings.TrimSpace(parentTree.String()) + "]")},
+	}))
+	u.set(treeID, formatDict([]dictEntry{
+		{key: "Type", value: []byte("/StructTreeRoot")},
+		{key: "K", value: []byte(ref(documentID))},
+		{key: "ParentTree", value: []byte(ref(parentTreeID))},
+		{key: "ParentTreeNextKey", value: []byte(strconv.Itoa(key))},
+	}))
+
+	catalog, err := u.dict(rootID)
+	if err != nil {
+		return nil, err
+	}
+	catalog = dictSet(catalog, "MarkInfo", "<< /Marked true >>")
+	catalog = dictSet(catalog, "StructTreeRoot", ref(treeID))
+	catalog = dictSet(catalog, "ViewerPreferences", "<< /DisplayDocTitle true >>")
+	if lang != "" {
+		catalog = dictSet(catalog, "Lang", pdfTextString(lang))
+	}
+	u.set(rootID, formatDict(catalog))
+	return u.rewrite()
+}
+
+// contentTagger replaces the markers of the content streams of a document,
+// page by page, with marked-content sequences, collecting the children of
+// each structure element on the way.
+type contentTagger struct {
+	elems   []structElem
+	elemIDs []int      // Object number of each element.
+	kids    [][]string // Children of each element, and of the document last.
+	seen    []bool     // Whether each element was added to its parent.
+	stack   []int      // Open elements and artifacts, innermost last.
+	markers int        // Markers found so far.
+}
+
+// tagContent returns the content stream data with its markers replaced, and
+// the element owning each of its marked-content sequences.
+//
+// A sequence is opened before the first operator following a marker and
+// closed at the next marker. To nest properly with q/Q and BT/ET, a sequence
+// is closed before a Q or ET that would end inside it, and before a q or BT
+// whose block contains a marker; the operators of such a block are then
+// marked inside it.
+func (t *contentTagger) tagContent(data []byte, pageID int) ([]byte, []string, error) {
+	ops, err := contentOps(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// holdsMarker tells for every q and BT whether a marker comes before
+	// the matching Q or ET.
+	holdsMarker := make([]bool, len(ops))
+	var blocks []int
+	for i, op := range ops {
+		switch op.operator {
+		case "q", "BT":
+			blocks = append(blocks, i)
+		case "Q", "ET":
+			if len(blocks) > 0 {
+				blocks = blocks[:len(blocks)-1]
+			}
+		default:
+			if _, _, ok := op.marker(); ok {
+				for _, b := range blocks {
+					holdsMarker[b] = true
+				}
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	var mcids []string
+	open := false
+	closeSequence := func() {
+		if open {
+			out.WriteString("EMC\n")
+			open = false
+		}
+	}
+	depth, openDepth, last := 0, 0, 0
+	for i, op := range ops {
+		out.Write(data[last:op.start])
+		last = op.end
+
+		if kind, elem, ok := op.marker(); ok {
+			t.markers++
+			switch {
+			case kind == markBeginTag && elem < len(t.elems):
+				if !t.seen[elem] {
+					t.seen[elem] = true
+					parent := t.elems[elem].parent
+					if parent < 0 {
+						parent = len(t.elems)
+					}
+					t.kids[parent] = append(t.kids[parent], ref(t.elemIDs[elem]))
+				}
+				t.stack = append(t.stack, elem)
+			case kind == markBeginArtifact:
+				t.stack = append(t.stack, artifactElem)
+			case len(t.stack) > 0:
+				t.stack = t.stack[:len(t.stack)-1]
+			}
+			closeSequence()
+			// The marker is dropped with the line break that follows it.
+			if last < len(data) && data[last] == '\n' {
+				last++
+			}
+			continue
+		}
+
+		switch op.operator {
+		case "Q", "ET":
+			if depth == openDepth {
+				closeSequence()
+			}
+			depth--
+			out.Write(data[op.start:op.end])
+			continue
+		case "q", "BT":
+			if holdsMarker[i] {
+				closeSequence()
+				depth++
+				out.Write(data[op.start:op.end])
+				continue
+			}
+		}
+
+		if !open {
+			if len(t.stack) == 0 || t.stack[len(t.stack)-1] == artifactElem {
+				out.WriteString("/Artifact BMC\n")
+			} else {
+				elem := t.stack[len(t.stack)-1]
+				fmt.Fprintf(&out, "/%s <</MCID %d>> BDC\n", t.elems[elem].role, len(mcids))
+				t.kids[elem] = append(t.kids[elem], fmt.Sprintf("<</Type /MCR /Pg %s /MCID %d>>", ref(pageID), len(mcids)))
+				mcids = append(mcids, ref(t.elemIDs[elem]))
+			}
+			open, openDepth = true, depth
+		}
+		out.Write(data[op.start:op.end])
+		if op.operator == "q" || op.operator == "BT" {
+			depth++
+		}
+	}
+	out.Write(data[last:])
+	if open && !bytes.HasSuffix(out.Bytes(), []byte("\n")) {
+		out.WriteByte('\n')
+	}
+	closeSequence()
+	return out.Bytes(), mcids, nil
+}
+
+// contentOp is an operator of a content stream with its operands.
+type contentOp struct {
+	start, end int      // Byte range of the operands and operator.
+	operands   [][]byte // Raw operands.
+	operator   string
+}
+
+// marker returns the kind and element of a marker written by mark.
+func (op contentOp) marker() (kind, elem int, ok bool) {
+	if op.operator != "d" || len(op.operands) != 2 {
+		return 0, 0, false
+	}
+	phase, err := strconv.ParseFloat(string(op.operands[1]), 64)
+	if err != nil || phase != tagMarkerPhase {
+		return 0, 0, false
+	}
+	values := bytes.Fields(bytes.Trim(op.operands[0], "[]"))
+	if len(values) != 2 {
+		return 0, 0, false
+	}
+	k, err1 := strconv.ParseFloat(string(values[0]), 64)
+	e, err2 := strconv.ParseFloat(string(values[1]), 64)
+	if err1 != nil || err2 != nil {
+		return 0, 0, false
+	}
+	return int(k), int(e), true
+}
+
+// contentOps splits content stream data into its operators.
+func contentOps(data []byte) ([]contentOp, error) {
+	var ops []contentOp
+	var operands [][]byte
+	start := -1
+	for i := skipSpace(data, 0); i < len(data); i = skipSpace(data, i) {
+		if data[i] == '%' {
+			for i < len(data) && data[i] != '\n' && data[i] != '\r' {
+				i++
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+		switch c := data[i]; {
+		case c == '(' || c == '<' || c == '[' || c == '/':
+			value, end, err := readValue(data, i)
+			if err != nil {
+				return nil, err
+			}
+			operands = append(operands, value)
+			i = end
+		case c == ')' || c == '>' || c == ']':
+			return nil, fmt.Errorf("unexpected %q in content stream", c)
+		case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+			end := nameEnd(data, i)
+			operands = append(operands, data[i:end])
+			i = end
+		default:
+			end := nameEnd(data, i)
+			operator := string(data[i:end])
+			if operator == "BI" {
+				return nil, errors.New("inline images are not supported")
+			}
+			ops = append(ops, contentOp{start: start, end: end, operands: operands, operator: operator})
+			operands, start = nil, -1
+			i = end
+		}
+	}
+	if len(operands) > 0 {
+		return nil, errors.New("content stream ends with operands")
+	}
+	return ops, nil
+}
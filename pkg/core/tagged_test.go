@@ -0,0 +1,301 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// taggedStructure returns the structure types of all structure elements of a
+// tagged PDF in object order, and the decoded content stream of every page.
+func taggedStructure(t *testing.T, pdf []byte) ([]string, []string) {
+	u, err := newPDFUpdate(pdf)
+	require.NoError(t, err)
+
+	var roles []string
+	for _, id := range u.ids() {
+		if dict, err := u.dict(id); err == nil && string(dictGet(dict, "Type")) == "/StructElem" {
+			roles = append(roles, strings.TrimPrefix(string(dictGet(dict, "S")), "/"))
+		}
+	}
+	return roles, pageContents(t, pdf)
+}
+
+func TestRenderHTMLLikeToBuffer_Tagged(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	r.Tagged = true
+
+	buf, err := r.RenderHTMLLikeToBuffer(`<html lang="de-DE"><body>
+		<h2>Summary</h2>
+		<p>Body text</p>
+		<ul><li>Item</li></ul>
+		<table><thead><tr><th>Name</th></tr></thead><tbody><tr><td>Alice</td></tr></tbody></table>
+		<img alt="Company logo" src="data:image/png;base64,iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mP8z8BQDwAEhQGAhKmMIQAAAABJRU5ErkJggg==">
+	</body></html>`)
+	require.NoError(t, err)
+
+	u, err := newPDFUpdate(buf.Bytes())
+	require.NoError(t, err)
+	rootID, _ := u.root()
+	catalog, err := u.dict(rootID)
+	require.NoError(t, err)
+	assert.Equal(t, "<< /Marked true >>", string(dictGet(catalog, "MarkInfo")))
+	assert.Equal(t, pdfTextString("de-DE"), string(dictGet(catalog, "Lang")))
+	assert.NotNil(t, dictGet(catalog, "StructTreeRoot"))
+
+	roles, contents := taggedStructure(t, buf.Bytes())
+	assert.Equal(t, []string{
+		"Document", "H2", "P", "L", "LI", "Lbl", "LBody",
+		"Table", "THead", "TR", "TH", "TBody", "TR", "TD", "Figure",
+	}, roles)
+	assert.Contains(t, buf.String(), "/Alt "+pdfTextString("Company logo"))
+
+	require.Len(t, contents, 1)
+	assert.Contains(t, contents[0], "/H2 <</MCID 0>> BDC")
+	assert.Contains(t, contents[0], "/Lbl <</MCID 2>> BDC")
+	assert.Contains(t, contents[0], "/LBody <</MCID 3>> BDC")
+	assert.Contains(t, contents[0], "/TD <</MCID 5>> BDC")
+	assert.Contains(t, contents[0], "/Figure <</MCID 6>> BDC")
+	assert.Contains(t, contents[0], "/Artifact BMC", "page numbers and table borders are artifacts")
+	assert.NotContains(t, contents[0], "-7281", "markers are removed")
+}
+
+func TestRenderHTMLLikeToBuffer_TaggedAcrossPages(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	r.Tagged = true
+
+	buf, err := r.RenderHTMLLikeToBuffer("<p>" + strings.Repeat("A paragraph long enough to run across pages. ", 400) + "</p>")
+	require.NoError(t, err)
+
+	roles, contents := taggedStructure(t, buf.Bytes())
+	assert.Equal(t, []string{"Document", "P"}, roles)
+	require.Greater(t, len(contents), 1)
+
+	sequence := regexp.MustCompile(`(?m)^(/\w+) (?:<</MCID \d+>> BDC|BMC)$`)
+	for i, content := range contents {
+		var tags []string
+		for _, m := range sequence.FindAllStringSubmatch(content, -1) {
+			tags = append(tags, m[1])
+		}
+		// Decorations come first, then the paragraph continues, then the page number.
+		assert.Equal(t, []string{"/Artifact", "/P", "/Artifact"}, tags, "page %d", i+1)
+		assert.Equal(t, len(tags), strings.Count(content, "EMC\n"), "page %d", i+1)
+	}
+
+	// The paragraph refers to its marked content on every page.
+	assert.Equal(t, len(contents), bytes.Count(buf.Bytes(), []byte("/Type /MCR")))
+}
+
+func TestRenderHTMLLikeToBuffer_TaggedKeepsDrawing(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	body := `<h1>Title</h1>
+		<p>See <a href="https://example.com">the site</a>.</p>
+		<ol><li>First</li><li>Second<ul><li>Nested</li></ul></li></ol>
+		<table><tr><th>Name</th></tr><tr><td>Alice</td></tr></table>`
+	render := func(tagged bool) []string {
+		r, _ := NewRenderer(defaultFontSizes(), true)
+		r.Tagged = tagged
+		r.LinkStyle.Underline = true
+		buf, err := r.RenderHTMLLikeToBuffer(body)
+		require.NoError(t, err)
+		return pageContents(t, buf.Bytes())
+	}
+	plain, tagged := render(false), render(true)
+	require.Len(t, tagged, len(plain))
+
+	// Without its marked-content operators, a tagged page draws exactly what
+	// the untagged page draws: markers leave no dash pattern behind, so table
+	// borders and link underlines keep theirs.
+	marked := regexp.MustCompile(`(?m)^(/\w+ (<</MCID \d+>> BDC|BMC)|EMC)\n`)
+	for i := range plain {
+		assert.Equal(t, plain[i], marked.ReplaceAllString(tagged[i], ""), "page %d", i+1)
+		assert.NotContains(t, tagged[i], " d\n", "page %d", i+1)
+		assertNesting(t, tagged[i])
+	}
+}
+
+func TestRenderHTMLLikeToBuffer_TaggedIsRewritten(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	body := strings.Repeat("<h2>Section</h2><p>Body text of the section.</p>", 80)
+	render := func(tagged bool) []byte {
+		r, _ := NewRenderer(defaultFontSizes(), true)
+		r.Tagged = tagged
+		buf, err := r.RenderHTMLLikeToBuffer(body)
+		require.NoError(t, err)
+		return buf.Bytes()
+	}
+	plain, tagged := render(false), render(true)
+
+	assert.Equal(t, 1, bytes.Count(tagged, []byte("startxref")), "no incremental update")
+	assert.Less(t, len(tagged), 2*len(plain), "the original content streams are not kept")
+}
+
+func TestRenderHTMLLikeToBuffer_UntaggedHasNoMarkers(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	r.pdf.SetNoCompression()
+
+	buf, err := r.RenderHTMLLikeToBuffer(`<h1>Title</h1><table><tr><td>Cell</td></tr></table>`)
+	require.NoError(t, err)
+	assert.NotContains(t, buf.String(), "-7281")
+	assert.NotContains(t, buf.String(), "/StructTreeRoot")
+}
+
+func TestRenderHTMLLikeToBuffer_TaggedRejectsProtection(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	r.Tagged = true
+	r.Protection = &Protection{UserPassword: "secret"}
+
+	_, err := r.RenderHTMLLikeToBuffer(`<p>Body</p>`)
+	assert.ErrorContains(t, err, "password protection")
+}
+
+// assertNesting checks that the marked-content sequences of a content stream
+// nest properly with q/Q and BT/ET, and that everything drawn is marked.
+func assertNesting(t *testing.T, content string) {
+	ops, err := contentOps([]byte(content))
+	require.NoError(t, err)
+	var stack []string
+	for _, op := range ops {
+		open := op.operator
+		switch op.operator {
+		case "Tj", "TJ", "Do", "S", "f", "f*", "B", "b", "sh":
+			assert.Contains(t, stack, "EMC", "%s outside a marked-content sequence", op.operator)
+			continue
+		case "BDC", "BMC":
+			open = "EMC"
+		case "q":
+			open = "Q"
+		case "BT":
+			open = "ET"
+		case "EMC", "Q", "ET":
+			require.NotEmpty(t, stack, "%s closes nothing", op.operator)
+			require.Equal(t, stack[len(stack)-1], op.operator, "%s closes a sequence opened by another operator", op.operator)
+			stack = stack[:len(stack)-1]
+			continue
+		default:
+			continue
+		}
+		stack = append(stack, open)
+	}
+	assert.Empty(t, stack, "unclosed operators")
+}
+
+func TestContentTagger_NestsWithGraphicsState(t *testing.T) {
+	elems := []structElem{{role: "P", parent: -1}, {role: "Figure", parent: -1}}
+	marker := func(kind, elem int) string {
+		return fmt.Sprintf("[%d.00 %d.00] -7281.00 d\n", kind, elem)
+	}
+	data := marker(markBeginTag, 0) +
+		"q\n1 0 0 1 0 0 cm\n" + marker(markEndTag, 0) + marker(markBeginTag, 1) + "/I1 Do\nQ\n" +
+		"BT\n(text) Tj\n" + marker(markEndTag, 1) + "ET\n" +
+		marker(markBeginArtifact, 0) + "0 0 10 10 re f\n" + marker(markEndArtifact, 0)
+
+	tagger := &contentTagger{elems: elems, elemIDs: []int{10, 11}, kids: make([][]string, 3), seen: make([]bool, 2)}
+	out, mcids, err := tagger.tagContent([]byte(data), 5)
+	require.NoError(t, err)
+	assert.Equal(t, 6, tagger.markers)
+	assert.NotContains(t, string(out), "-7281")
+	assertNesting(t, string(out))
+
+	// The q and BT holding markers are left outside the sequences.
+	assert.Equal(t, "q\n/P <</MCID 0>> BDC\n1 0 0 1 0 0 cm\nEMC\n/Figure <</MCID 1>> BDC\n/I1 Do\nEMC\nQ\n"+
+		"BT\n/Figure <</MCID 2>> BDC\n(text) Tj\nEMC\nET\n"+
+		"/Artifact BMC\n0 0 10 10 re f\nEMC\n", string(out))
+	assert.Equal(t, []string{"10 0 R", "11 0 R", "11 0 R"}, mcids)
+}
+
+func TestContentTagger_ClosesBeforeEnclosingQ(t *testing.T) {
+	elems := []structElem{{role: "P", parent: -1}}
+	data := "q\n[1.00 0.00] -7281.00 d\n(a) Tj\nQ\n(b) Tj\n[2.00 0.00] -7281.00 d\n"
+
+	tagger := &contentTagger{elems: elems, elemIDs: []int{10}, kids: make([][]string, 2), seen: make([]bool, 1)}
+	out, _, err := tagger.tagContent([]byte(data), 5)
+	require.NoError(t, err)
+	assertNesting(t, string(out))
+	assert.Equal(t, "q\n/P <</MCID 0>> BDC\n(a) Tj\nEMC\nQ\n/P <</MCID 1>> BDC\n(b) Tj\nEMC\n", string(out))
+}
+
+func TestRenderHTMLLikeToBuffer_TaggedNesting(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	r.Tagged = true
+	r.Watermark = &Watermark{Text: "DRAFT", Angle: 45}
+
+	buf, err := r.RenderHTMLLikeToBuffer(`<h1>Title</h1>
+		<div class="row"><div class="col" style="width: 50%"><p>Left</p></div><div class="col" style="width: 50%"><p>Right</p></div></div>
+		<p>Logo <img alt="Logo" src="data:image/png;base64,iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mP8z8BQDwAEhQGAhKmMIQAAAABJRU5ErkJggg=="> inline</p>
+		<table><tr><th>Name</th></tr><tr><td>Alice</td></tr></table>`)
+	require.NoError(t, err)
+	for _, content := range pageContents(t, buf.Bytes()) {
+		assertNesting(t, content)
+	}
+}
+
+func TestRenderHTMLLikeToBuffer_TaggedLinks(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	r.Tagged = true
+
+	buf, err := r.RenderHTMLLikeToBuffer(`<p>See <a href="https://example.com">the site</a>.</p>
+		<a href="https://example.org">Standalone link</a>`)
+	require.NoError(t, err)
+
+	roles, contents := taggedStructure(t, buf.Bytes())
+	assert.Equal(t, []string{"Document", "P", "Link", "Link"}, roles)
+	assert.Contains(t, contents[0], "/Link <</MCID 1>> BDC")
+
+	u, err := newPDFUpdate(buf.Bytes())
+	require.NoError(t, err)
+	var parents []string
+	for _, id := range u.ids() {
+		if dict, err := u.dict(id); err == nil && string(dictGet(dict, "Subtype")) == "/Link" {
+			parents = append(parents, string(dictGet(dict, "StructParent")))
+			assert.Contains(t, buf.String(), "/Obj "+ref(id), "the Link element refers to its annotation")
+		}
+	}
+	assert.Equal(t, []string{"1", "2"}, parents, "annotations follow the page in the parent tree")
+	assert.Contains(t, buf.String(), "/ParentTreeNextKey 3")
+}
+
+func TestTagPDF_MissingMarkers(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	r.Tagged = true
+	_, err := r.RenderHTMLLikeToBuffer(`<p>Body</p>`)
+	require.NoError(t, err)
+
+	plain, _ := NewRenderer(defaultFontSizes(), true)
+	untagged, err := plain.RenderHTMLLikeToBuffer(`<p>Body</p>`)
+	require.NoError(t, err)
+
+	// A layout that wrote markers gopdf did not keep cannot be tagged.
+	_, err = tagPDF(untagged.Bytes(), taggedLayout{elems: r.structElems, markers: r.markers}, "")
+	assert.ErrorContains(t, err, "structure markers")
+}
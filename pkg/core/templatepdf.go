@@ -0,0 +1,73 @@
+// File: renderer/templatepdf.go
+package core
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/signintech/gopdf"
+)
+
+// pdfPageBox is the page boundary used when importing pages of existing PDFs.
+const pdfPageBox = "/MediaBox"
+
+// TemplatePDFDecorator draws the page of an existing PDF selected by
+// TemplatePDF beneath everything else, e.g. a letterhead. The page is imported
+// on first use and stretched to the page size.
+type TemplatePDFDecorator struct{}
+
+func (TemplatePDFDecorator) OnPageStart(ctx PageContext) {
+	r := ctx.r
+	if r.TemplatePDF == nil {
+		return
+	}
+	if !r.templateImported {
+		r.templateImported = true
+		r.templateID = -1
+		err := recoverImport(func() {
+			r.templateID = r.pdf.ImportPage(r.TemplatePDF.Path, r.TemplatePDF.Page, pdfPageBox)
+		})
+		if err != nil {
+			log.Println("Template PDF skipped:", err)
+		}
+	}
+	if r.templateID >= 0 {
+		ctx.PDF.UseImportedTemplate(r.templateID, 0, 0, ctx.Width, ctx.Height)
+	}
+}
+
+func (TemplatePDFDecorator) OnPageEnd(PageContext) {}
+
+// checkTemplatePDF reports whether t refers to an existing page of a readable
+// PDF file.
+func checkTemplatePDF(t *TemplatePDF) error {
+	if _, err := os.Stat(t.Path); err != nil {
+		return fmt.Errorf("template PDF: %w", err)
+	}
+	var sizes map[int]map[string]map[string]float64
+	err := recoverImport(func() {
+		pdf := &gopdf.GoPdf{}
+		pdf.Start(gopdf.Config{PageSize: *gopdf.PageSizeA4})
+		sizes = pdf.GetPageSizes(t.Path)
+	})
+	if err != nil {
+		return fmt.Errorf("template PDF %s: %w", t.Path, err)
+	}
+	if t.Page < 1 || t.Page > len(sizes) {
+		return fmt.Errorf("template PDF %s has no page %d", t.Path, t.Page)
+	}
+	return nil
+}
+
+// recoverImport runs fn, which imports pages through gopdf, and returns the
+// panic the underlying PDF parser raises on unreadable input as an error.
+func recoverImport(fn func()) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("cannot import PDF: %v", p)
+		}
+	}()
+	fn()
+	return nil
+}
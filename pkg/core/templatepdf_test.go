@@ -0,0 +1,60 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeLetterhead renders a one-page PDF into a temporary file and returns its path.
+func writeLetterhead(t *testing.T) string {
+	r, _ := NewRenderer(defaultFontSizes(), false)
+	path := filepath.Join(t.TempDir(), "letterhead.pdf")
+	require.NoError(t, os.WriteFile(path, renderPages(t, r, "<h1>ACME Corp.</h1>"), 0o644))
+	return path
+}
+
+func TestTemplatePDFDecorator(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	r.TemplatePDF = &TemplatePDF{Path: writeLetterhead(t), Page: 1}
+
+	pdf := renderPages(t, r, "<p>Dear customer</p><pagebreak/><p>Second page</p>")
+
+	contents := pageContents(t, pdf)
+	require.Len(t, contents, 2)
+	for i, content := range contents {
+		assert.Contains(t, content, "/GOFPDITPL0 Do", "page %d", i+1)
+	}
+}
+
+func TestTemplatePDFDecorator_Unreadable(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	path := filepath.Join(t.TempDir(), "broken.pdf")
+	require.NoError(t, os.WriteFile(path, []byte("not a PDF"), 0o644))
+
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	r.TemplatePDF = &TemplatePDF{Path: path, Page: 1}
+
+	// The page is rendered without the template.
+	pdf := renderPages(t, r, "<p>Body</p>")
+	assert.NotContains(t, string(pdf), "GOFPDITPL")
+}
+
+func TestCheckTemplatePDF(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	path := writeLetterhead(t)
+
+	assert.NoError(t, checkTemplatePDF(&TemplatePDF{Path: path, Page: 1}))
+	assert.ErrorContains(t, checkTemplatePDF(&TemplatePDF{Path: path, Page: 2}), "has no page 2")
+	assert.ErrorContains(t, checkTemplatePDF(&TemplatePDF{Path: filepath.Join(t.TempDir(), "missing.pdf"), Page: 1}), "no such file")
+}
@@ -0,0 +1,158 @@
+// File: renderer/toc.go
+package core
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/signintech/gopdf"
+	"golang.org/x/net/html"
+)
+
+// tocNumberWidth is the width reserved at the right edge of a TOC line for the page number.
+const tocNumberWidth = 30.0
+
+// tocEntry is a heading listed in the table of contents.
+type tocEntry struct {
+	level       int    // Heading level (1–3).
+	text        string // Heading text.
+	anchor      string // Id of the heading, used as the link target and to look up its page.
+	placeholder string // Name of the page-number placeholder drawn in the TOC.
+}
+
+// collectTOCEntries is the first pass of table of contents generation. It runs
+// over the parsed document before layout and lists every h1–h3 heading up to
+// the configured level, giving headings without an id a generated one so they
+// can be linked to. Headings nested inside paragraphs or tables are skipped,
+// since they are not laid out as headings.
+func (r *Renderer) collectTOCEntries(n *html.Node) {
+	if n.Type == html.ElementNode {
+		switch n.Data {
+		case "p", "table":
+			return
+		case "h1", "h2", "h3":
+			level := int(n.Data[1] - '0')
+			if level > r.tocMaxLevel() {
+				return
+			}
+			id := getAttr(n, "id")
+			if id == "" {
+				id = fmt.Sprintf("toc-%d", len(r.tocEntries)+1)
+				n.Attr = append(n.Attr, html.Attribute{Key: "id", Val: id})
+			}
+			r.tocEntries = append(r.tocEntries, tocEntry{
+				level:       level,
+				text:        GetTextContent(n),
+				anchor:      id,
+				placeholder: fmt.Sprintf("toc-page-%d", len(r.tocEntries)+1),
+			})
+			return
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		r.collectTOCEntries(c)
+	}
+}
+
+// tocMaxLevel returns the deepest heading level listed in the table of contents.
+func (r *Renderer) tocMaxLevel() int {
+	if r.TableOfContents == nil || r.TableOfContents.MaxLevel <= 0 || r.TableOfContents.MaxLevel > 3 {
+		return 3
+	}
+	return r.TableOfContents.MaxLevel
+}
+
+// hasTOCElement reports whether the document contains a <toc> placeholder element.
+func hasTOCElement(n *html.Node) bool {
+	if n.Type == html.ElementNode && n.Data == "toc" {
+		return true
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if hasTOCElement(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// renderTOC draws the table of contents at the current position. Each entry is
+// indented by heading level, followed by dot leaders and a placeholder for the
+// page number, and the whole line links to the heading. Page numbers are only
+// known once the document has been laid out, so fillTOCPageNumbers writes them
+// into the placeholders afterwards.
+func (r *Renderer) renderTOC() {
+	if r.tocRendered || r.outOfFlow() {
+		return
+	}
+	r.tocRendered = true
+	defer r.endTag(r.beginTag("TOC", ""))
+
+	if r.TableOfContents != nil && r.TableOfContents.Title != "" {
+		r.renderTextBlock([]TextChunk{{Text: r.TableOfContents.Title}}, r.FontSize.H2, 25, AlignLeft)
+		r.y += 5
+	}
+
+	lineHeight := r.FontSize.P * 4 / 3
+	right := r.left + r.width
+	for _, entry := range r.tocEntries {
+		indent := float64(entry.level-1) * 15
+		chunk := TextChunk{Text: entry.text, Bold: entry.level == 1}
+		lines := r.layoutChunks([]TextChunk{chunk}, r.width-indent-tocNumberWidth-20, r.FontSize.P, lineHeight)
+		if len(lines) == 0 {
+			continue
+		}
+
+		r.checkPageBreak(linesHeight(lines))
+		tag := r.beginTag("TOCI", "")
+		top := r.y
+		for _, line := range lines {
+			r.drawTextLine(line, r.left+indent, r.y, r.FontSize.P, 0)
+			r.y += line.height
+		}
+		lastY := r.y - lines[len(lines)-1].height
+
+		_ = r.pdf.SetFont("Arial", "", r.FontSize.P)
+		leaderStart := r.left + indent + lines[len(lines)-1].width + 4
+		r.drawText(dotLeaders(r.pdf, right-tocNumberWidth-4-leaderStart), leaderStart, lastY)
+
+		r.pdf.SetX(right - tocNumberWidth)
+		r.pdf.SetY(lastY)
+		if err := r.pdf.PlaceHolderText(entry.placeholder, tocNumberWidth); err != nil {
+			log.Println("TOC page number placeholder failed:", err)
+		}
+		r.pdf.AddInternalLink(entry.anchor, r.left+indent, top, right-r.left-indent, r.y-top)
+		r.endTag(tag)
+	}
+	r.y += 10
+}
+
+// dotLeaders returns a row of dots that fits within width in the current font.
+func dotLeaders(pdf *gopdf.GoPdf, width float64) string {
+	dotWidth, _ := pdf.MeasureTextWidth(" .")
+	if dotWidth <= 0 || width <= 0 {
+		return ""
+	}
+	return strings.Repeat(" .", int(width/dotWidth))
+}
+
+// fillTOCPageNumbers is the second pass of table of contents generation: once
+// every heading has been placed, it writes their page numbers into the
+// placeholders reserved by renderTOC.
+func (r *Renderer) fillTOCPageNumbers() {
+	if !r.tocRendered {
+		return
+	}
+	// gopdf measures the filled-in text with the current font, so select the
+	// font the placeholders were created with.
+	_ = r.pdf.SetFont("Arial", "", r.FontSize.P)
+	for _, entry := range r.tocEntries {
+		page := ""
+		if n, ok := r.anchorPages[entry.anchor]; ok {
+			page = r.PageNumbers.pageLabel(n)
+		}
+		if err := r.pdf.FillInPlaceHoldText(entry.placeholder, page, gopdf.Right); err != nil {
+			log.Println("TOC page number fill failed:", err)
+		}
+	}
+}
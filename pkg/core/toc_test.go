@@ -0,0 +1,67 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/html"
+)
+
+func TestCollectTOCEntries(t *testing.T) {
+	doc, _ := html.Parse(strings.NewReader(`
+		<h1>Intro</h1>
+		<h2 id="scope">Scope</h2>
+		<h3>Detail</h3>
+		<h4>Too deep</h4>
+		<table><tr><td><h2>In a cell</h2></td></tr></table>`))
+
+	r := &Renderer{TableOfContents: &TableOfContents{MaxLevel: 2}}
+	r.collectTOCEntries(doc)
+
+	assert.Len(t, r.tocEntries, 2)
+	assert.Equal(t, tocEntry{level: 1, text: "Intro", anchor: "toc-1", placeholder: "toc-page-1"}, r.tocEntries[0])
+	assert.Equal(t, "scope", r.tocEntries[1].anchor)
+	assert.Equal(t, "toc-1", getAttr(findNode(doc, "h1"), "id"), "generated ids are added to the document")
+}
+
+func TestRenderHTMLLikeToBuffer_TableOfContentsOnOwnPage(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	r.TableOfContents = &TableOfContents{Title: "Contents"}
+	r.pdf.SetNoCompression()
+
+	var body strings.Builder
+	for _, title := range []string{"Summary", "Findings", "Appendix"} {
+		body.WriteString("<h1>" + title + "</h1>")
+		body.WriteString("<h2>" + title + " details</h2>")
+		for i := 0; i < 30; i++ {
+			body.WriteString("<p>Lorem ipsum dolor sit amet, consectetur adipiscing elit.</p>")
+		}
+	}
+
+	buf, err := r.RenderHTMLLikeToBuffer(body.String())
+	assert.NoError(t, err)
+
+	assert.Len(t, r.tocEntries, 6)
+	assert.Equal(t, 2, r.anchorPages["toc-1"], "content starts after the TOC page")
+	assert.Greater(t, r.anchorPages["toc-5"], r.anchorPages["toc-3"])
+	assert.Equal(t, 6, strings.Count(buf.String(), "/Dest ["))
+}
+
+func TestRenderHTMLLikeToBuffer_TOCElement(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+
+	_, err := r.RenderHTMLLikeToBuffer(`<h1 id="title">Report</h1><toc/><h2>First</h2><p>Text</p><h2>Second</h2>`)
+	assert.NoError(t, err)
+
+	assert.True(t, r.tocRendered)
+	assert.Len(t, r.tocEntries, 3)
+	assert.Equal(t, 1, r.anchorPages["title"], "the TOC is drawn in place, not on its own page")
+	assert.Equal(t, 1, r.anchorPages["toc-3"])
+}
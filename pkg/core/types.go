@@ -1,5 +1,11 @@
 package core
 
+import (
+	"crypto"
+	"crypto/x509"
+	"time"
+)
+
 const (
 	// pageHeight defines the total height of an A4 PDF page in points.
 	pageHeight = 842.0
@@ -16,9 +22,15 @@ const (
 
 // TextChunk represents a piece of styled text with font style information.
 type TextChunk struct {
-	Text   string // The actual text content.
-	Italic bool   // Whether the text is italicized.
-	Bold   bool   // Whether the text is bold.
+	Text      string  // The actual text content.
+	Italic    bool    // Whether the text is italicized.
+	Bold      bool    // Whether the text is bold.
+	Color     *Color  // Text color; nil uses the default black.
+	Link      string  // Target of the enclosing <a href>, if any.
+	LineBreak bool    // Whether the chunk is a forced line break (<br>).
+	Image     string  // Source of an inline <img> (data URI or file:// path).
+	ImageW    float64 // Inline image width in points; zero uses the line height.
+	ImageH    float64 // Inline image height in points; zero uses the line height.
 }
 
 // FontSizes defines the font size configuration for various text elements in the PDF.
@@ -36,3 +48,174 @@ type FontPaths struct {
 	Bold    string
 	Italic  string
 }
+
+// Color represents an RGB color used for fills, strokes, and text.
+type Color struct {
+	R, G, B uint8
+}
+
+// TableBorder defines how the borders of table cells are drawn.
+type TableBorder string
+
+const (
+	// TableBorderCollapse draws a single shared grid line between adjacent cells.
+	TableBorderCollapse TableBorder = "collapse"
+	// TableBorderSeparate outlines every cell on its own, separated by CellSpacing.
+	TableBorderSeparate TableBorder = "separate"
+	// TableBorderNone draws no cell borders at all.
+	TableBorderNone TableBorder = "none"
+)
+
+// TableStyle defines the visual styling applied to <table> elements.
+type TableStyle struct {
+	HeaderFill  *Color      // Background for header rows (<thead> or rows made only of <th>); nil for none.
+	StripeFill  *Color      // Background for every second body row (zebra striping); nil for none.
+	FooterFill  *Color      // Background for <tfoot> rows; nil for none.
+	FooterBold  bool        // Whether <tfoot> rows are rendered in bold.
+	Border      TableBorder // Border mode; empty behaves like TableBorderCollapse.
+	BorderWidth float64     // Border line width in points; zero or less uses 1.
+	BorderColor Color       // Border line color.
+	CellSpacing float64     // Gap between cells when Border is TableBorderSeparate.
+}
+
+// LinkStyle defines how hyperlink (<a href>) text is drawn.
+type LinkStyle struct {
+	Color     Color // Text color for links that do not set their own color.
+	Underline bool  // Whether link text is underlined.
+}
+
+// TableOfContents configures the automatically generated table of contents.
+type TableOfContents struct {
+	Title    string // Title drawn above the entries; empty for none.
+	MaxLevel int    // Deepest heading level listed (1–3); zero lists h1–h3.
+}
+
+// PageNumberStyle defines the numbering system used for page numbers.
+type PageNumberStyle string
+
+const (
+	// PageNumberDecimal numbers pages 1, 2, 3, ...
+	PageNumberDecimal PageNumberStyle = "decimal"
+	// PageNumberRomanLower numbers pages i, ii, iii, ...
+	PageNumberRomanLower PageNumberStyle = "lower-roman"
+	// PageNumberRomanUpper numbers pages I, II, III, ...
+	PageNumberRomanUpper PageNumberStyle = "upper-roman"
+)
+
+// PageNumberPosition defines whether page numbers are drawn in the header or footer.
+type PageNumberPosition string
+
+const (
+	PageNumberFooter PageNumberPosition = "footer"
+	PageNumberHeader PageNumberPosition = "header"
+)
+
+// PageNumberFormat configures how page numbers are drawn.
+type PageNumberFormat struct {
+	Template         string             // Text with {page} and {total} placeholders; empty uses "Page {page}".
+	Style            PageNumberStyle    // Numbering style of the main pages; empty means decimal.
+	Position         PageNumberPosition // Header or footer; empty means footer.
+	Align            Alignment          // Horizontal alignment; empty means right.
+	StartAt          int                // Number shown on the first main page; zero means 1.
+	Total            int                // Number shown for {total}; zero means the number of the last page.
+	FrontMatterPages int                // Leading pages numbered separately in FrontMatterStyle.
+	FrontMatterStyle PageNumberStyle    // Style of the front matter numbers; empty means lower-roman.
+}
+
+// Pagination controls how paragraphs are split across pages.
+type Pagination struct {
+	Orphans int // Minimum lines of a paragraph left at the bottom of a page.
+	Widows  int // Minimum lines of a paragraph carried over to the top of the next page.
+}
+
+// Watermark is text and/or an image drawn across the middle of every page.
+type Watermark struct {
+	Text     string  // Watermark text, e.g. "DRAFT" or "CONFIDENTIAL" (optional).
+	Image    string  // Base64 data URI of a watermark image (optional).
+	Opacity  float64 // Opacity from 0 to 1; zero uses 0.15.
+	Angle    float64 // Rotation in degrees, counter-clockwise.
+	FontSize float64 // Text size in points; zero uses 60.
+	Color    Color   // Text color.
+	Above    bool    // Draw over the content instead of beneath it.
+}
+
+// ImageFit defines how a page image fills its box.
+type ImageFit string
+
+const (
+	// ImageFitStretch scales the image to the box, ignoring its aspect ratio.
+	ImageFitStretch ImageFit = "stretch"
+	// ImageFitContain scales the image to fit inside the box, centered.
+	ImageFitContain ImageFit = "contain"
+	// ImageFitCover scales the image to cover the box, cropping what overflows.
+	ImageFitCover ImageFit = "cover"
+)
+
+// PageImageOptions places a background, header, or footer image on the page.
+type PageImageOptions struct {
+	X, Y          float64  // Top-left corner of the image box.
+	Width, Height float64  // Size of the image box.
+	Fit           ImageFit // How the image fills the box; empty stretches it.
+	Opacity       float64  // Opacity from 0 to 1; zero draws the image opaque.
+	FirstPageOnly bool     // Draw the image on the first page only.
+	SkipFirstPage bool     // Draw the image on every page except the first.
+}
+
+// Attachment is a file embedded in the PDF, such as the data a report was
+// generated from.
+type Attachment struct {
+	Name        string // File name shown by PDF viewers.
+	MimeType    string // Media type, e.g. "application/json"; optional.
+	Data        []byte // File contents.
+	Description string // Description shown by PDF viewers; optional.
+}
+
+// TemplatePDF selects a page of an existing PDF file, drawn beneath the content
+// of every page, e.g. a letterhead.
+type TemplatePDF struct {
+	Path string // Path of the PDF file.
+	Page int    // Page to draw, starting at 1.
+}
+
+// Metadata holds the document properties written to the PDF's information
+// dictionary.
+type Metadata struct {
+	Title        string    // Document title; empty uses the template's <title>.
+	Author       string    // Person or organization that created the document.
+	Subject      string    // Subject of the document.
+	Keywords     string    // Keywords, usually comma-separated.
+	Creator      string    // Application that created the source document.
+	Producer     string    // Application that produced the PDF.
+	CreationDate time.Time // Creation date; zero uses the time of rendering.
+}
+
+// Permission is a set of operations readers may perform on a protected PDF
+// without the owner password. Values can be combined with |.
+type Permission int
+
+const (
+	// PermissionPrint allows printing the document.
+	PermissionPrint Permission = 4
+	// PermissionModify allows changing the document.
+	PermissionModify Permission = 8
+	// PermissionCopy allows copying text and images out of the document.
+	PermissionCopy Permission = 16
+)
+
+// Protection encrypts a PDF with a user and owner password.
+type Protection struct {
+	UserPassword  string     // Password needed to open the document; empty opens it without one.
+	OwnerPassword string     // Password that lifts all restrictions; empty generates a random one.
+	Permissions   Permission // Operations allowed without the owner password.
+}
+
+// SignOptions configures SignPDF.
+type SignOptions struct {
+	Signer       crypto.Signer       // Private key of the signer (RSA or ECDSA).
+	Certificates []*x509.Certificate // Signing certificate, followed by its chain.
+	Field        string              // Name of the <signature> box to sign; empty signs the first unsigned one.
+	Reason       string              // Reason for signing (optional).
+	Location     string              // Where the document was signed (optional).
+	ContactInfo  string              // How to reach the signer (optional).
+	SigningTime  time.Time           // Time of signing; zero uses the current time.
+}
@@ -0,0 +1,96 @@
+// File: renderer/watermark.go
+package core
+
+import (
+	"bytes"
+	"image"
+	_ "image/jpeg" // Register decoders for watermark image sizes.
+	_ "image/png"
+	"log"
+
+	"github.com/signintech/gopdf"
+)
+
+const (
+	// defaultWatermarkOpacity is used when Watermark.Opacity is zero.
+	defaultWatermarkOpacity = 0.15
+
+	// defaultWatermarkFontSize is used when Watermark.FontSize is zero.
+	defaultWatermarkFontSize = 60.0
+)
+
+// drawWatermark draws the watermark text and/or image centered on the current
+// page, rotated by its angle and with its opacity.
+func (r *Renderer) drawWatermark() {
+	w := r.Watermark
+	opacity := w.Opacity
+	if opacity <= 0 || opacity > 1 {
+		opacity = defaultWatermarkOpacity
+	}
+	transparency, err := gopdf.NewTransparency(opacity, "")
+	if err != nil {
+		log.Println("Watermark skipped:", err)
+		return
+	}
+
+	centerX, centerY := 595.28/2, 841.89/2
+	r.pdf.Rotate(w.Angle, centerX, centerY)
+	defer r.pdf.RotateReset()
+
+	if w.Image != "" {
+		r.drawWatermarkImage(w.Image, centerX, centerY, transparency)
+	}
+	if w.Text != "" {
+		size := w.FontSize
+		if size <= 0 {
+			size = defaultWatermarkFontSize
+		}
+		_ = r.pdf.SetFont("Arial", "B", size)
+		r.pdf.SetTextColor(w.Color.R, w.Color.G, w.Color.B)
+		width, _ := r.pdf.MeasureTextWidth(w.Text)
+		r.pdf.SetX(centerX - width/2)
+		r.pdf.SetY(centerY - size/2)
+		err := r.pdf.CellWithOption(nil, w.Text, gopdf.CellOption{
+			Align:        gopdf.Left | gopdf.Top,
+			Transparency: &transparency,
+		})
+		if err != nil {
+			log.Println("Watermark text render failed:", err)
+		}
+		r.pdf.SetTextColor(0, 0, 0)
+	}
+}
+
+// drawWatermarkImage draws a data URI image centered on (x, y), scaled down to
+// fit within the content width while keeping its aspect ratio.
+func (r *Renderer) drawWatermarkImage(dataURI string, x, y float64, transparency gopdf.Transparency) {
+	data, err := decodeDataURI(dataURI)
+	if err != nil {
+		log.Println("Watermark image skipped:", err)
+		return
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || cfg.Width == 0 || cfg.Height == 0 {
+		log.Println("Watermark image skipped:", err)
+		return
+	}
+	holder, err := gopdf.ImageHolderByBytes(data)
+	if err != nil {
+		log.Println("Watermark image skipped:", err)
+		return
+	}
+
+	width, height := float64(cfg.Width), float64(cfg.Height)
+	if scale := 495 / max(width, height); scale < 1 {
+		width, height = width*scale, height*scale
+	}
+	err = r.pdf.ImageByHolderWithOptions(holder, gopdf.ImageOptions{
+		X:            x - width/2,
+		Y:            y - height/2,
+		Rect:         &gopdf.Rect{W: width, H: height},
+		Transparency: &transparency,
+	})
+	if err != nil {
+		log.Println("Watermark image render failed:", err)
+	}
+}
@@ -0,0 +1,62 @@
+package core
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderHTMLLikeToBuffer_WatermarkUnderContent(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	r.Watermark = &Watermark{Text: "DRAFT", Angle: 45}
+	r.pdf.SetNoCompression()
+
+	buf, err := r.RenderHTMLLikeToBuffer(`<p>First</p><pagebreak/><p>Second</p>`)
+	assert.NoError(t, err)
+
+	pdf := buf.String()
+	assert.Contains(t, pdf, "/ca 0.150", "the default opacity is used")
+	assert.Equal(t, 2, strings.Count(pdf, "/F2 60 Tf"), "one watermark per page")
+	assert.Less(t, strings.Index(pdf, "/F2 60 Tf"), strings.Index(pdf, "/F1 12 Tf"), "drawn before the content")
+}
+
+func TestRenderHTMLLikeToBuffer_WatermarkAboveContent(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	r.Watermark = &Watermark{Text: "CONFIDENTIAL", FontSize: 40, Opacity: 0.3, Color: Color{R: 255}, Above: true}
+	r.pdf.SetNoCompression()
+
+	buf, err := r.RenderHTMLLikeToBuffer(`<p>Body</p>`)
+	assert.NoError(t, err)
+
+	pdf := buf.String()
+	assert.Contains(t, pdf, "/ca 0.300")
+	assert.Contains(t, pdf, "1.000 0.000 0.000 rg")
+	assert.Greater(t, strings.Index(pdf, "/F2 40 Tf"), strings.Index(pdf, "/F1 12 Tf"), "drawn after the content")
+}
+
+func TestRenderHTMLLikeToBuffer_WatermarkImage(t *testing.T) {
+	if fontsMissing() {
+		t.Skip("Fonts not found")
+	}
+	r, _ := NewRenderer(defaultFontSizes(), true)
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 800, 400))))
+	r.Watermark = &Watermark{Image: "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())}
+	r.pdf.SetNoCompression()
+
+	out, err := r.RenderHTMLLikeToBuffer(`<p>Body</p>`)
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "/Subtype /Image")
+	assert.Contains(t, out.String(), "495.00 0 0\n 247.50", "scaled down to the content width")
+}
@@ -22,7 +22,10 @@ type PDFRenderer struct {
 }
 
 // NewPDFRenderer constructs a PDFRenderer using a given RendererFactory.
-// This removes tight coupling with image setup and font handling.
+// This removes tight coupling with image setup and font handling. PDF-only
+// options such as WithAttachment are reached through a type assertion:
+//
+//	pdf.NewPDFRenderer(report, tmpl, factory).(*pdf.PDFRenderer).WithAttachment(...)
 func NewPDFRenderer(
 	report interface{},
 	tmpl *template.Template,
@@ -47,12 +50,17 @@ func (p *PDFRenderer) Render(filename string) ([]byte, error) {
 		return nil, err
 	}
 
+	// The timestamp and the document's creation date are read from the same clock.
+	now := time.Now()
+	if renderer.Metadata.CreationDate.IsZero() {
+		renderer.Metadata.CreationDate = now
+	}
 	if p.includeTimestamp {
 		format := p.timestampFormat
 		if format == "" {
 			format = "2006-01-02 15:04:05"
 		}
-		renderer.TopRightTimestamp = time.Now().Format(format)
+		renderer.TopRightTimestamp = now.Format(format)
 	}
 
 	pdfBuffer, err := renderer.RenderHTMLLikeToBuffer(buf.String())
@@ -80,3 +88,14 @@ func (p *PDFRenderer) SetTimestampFormat(format string) interfaces.RendererInter
 	p.timestampFormat = format
 	return p
 }
+
+// WithAttachment embeds a file, such as the report data as JSON or CSV, in the
+// PDF so that it travels with the rendered report. It adds the file to the
+// renderer's factory through RendererFactory.WithAttachment, so other
+// renderers built from the same factory embed it as well. Attachments are not
+// allowed in PDF/A-2b or password-protected output: Render fails before
+// laying out the report when the factory sets WithPDFA or WithProtection.
+func (p *PDFRenderer) WithAttachment(name, mimeType string, data []byte) *PDFRenderer {
+	p.Factory.WithAttachment(name, mimeType, data)
+	return p
+}
@@ -112,3 +112,15 @@ func TestPDFRenderer_Render_WriteToFile(t *testing.T) {
 func TestPDFRenderer_ImplementsRendererInterface(t *testing.T) {
 	var _ interfaces.RendererInterface = NewPDFRenderer(nil, template.New("x"), &core.RendererFactory{})
 }
+
+func TestPDFRenderer_Render_WithAttachment(t *testing.T) {
+	factory := core.NewRendererFactory()
+	pdfRenderer := setupTestRenderer(t, `<p>{{.Title}}</p>`, factory)
+	pdfRenderer.WithAttachment("report.json", "application/json", []byte(`{"Title":"My Report"}`))
+	assert.Len(t, factory.Attachments, 1, "the attachment is added to the factory")
+	out, err := pdfRenderer.Render("")
+
+	assert.NoError(t, err)
+	assert.Contains(t, string(out), "/EmbeddedFiles")
+	assert.Contains(t, string(out), "/application#2Fjson")
+}